@@ -0,0 +1,258 @@
+// Command dagpi-bench drives a mix of data and image routes at a target rate against a Dagpi compatible API
+// and reports latency percentiles, error rates, throughput and rate limit hits per route.
+//
+//	dagpi-bench -fake -rps 200 -duration 30s -mix data=1,image/triggered=2
+//	dagpi-bench -base https://api.dagpi.xyz -token $DAGPI_TOKEN -rps 1 -mix data/joke=1
+//
+// There is no default target, either -fake or -base has to be given so the real API is never load tested by accident.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/contract"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/internal/routes"
+)
+
+func main() {
+	baseURL := flag.String("base", "", "base url of the API to load test, like "+dagpi.DefaultBaseURL)
+	token := flag.String("token", os.Getenv("DAGPI_TOKEN"), "API token, defaults to $DAGPI_TOKEN")
+	rps := flag.Float64("rps", 10, "requests per second to send")
+	duration := flag.Duration("duration", 30*time.Second, "how long to send requests for")
+	mixFlag := flag.String("mix", "data=1,image=1", "weighted routes to call, like data/joke=3,image/pixel=1. "+
+		"data and image stand for every route of that kind, sharing the weight")
+	concurrency := flag.Int("concurrency", 64, "most requests in flight, requests are skipped beyond it")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for a single request")
+	imageURL := flag.String("image-url", contract.DefaultImageURL, "input image for image routes")
+	seed := flag.Int64("seed", 1, "seed for picking routes from the mix")
+	fake := flag.Bool("fake", false, "run against an in process dagpitest server instead of -base")
+	fakeLatency := flag.Duration("fake-latency", 0, "latency added to every response of the fake server")
+	jsonOut := flag.Bool("json", false, "print the report as json")
+	flag.Parse()
+
+	if *fake == (*baseURL != "") {
+		fatal(errors.New("pass -fake to use an in process server or -base to name the API to load test"))
+	}
+	interval, err := tickInterval(*rps)
+	if err != nil {
+		fatal(err)
+	}
+	if *concurrency <= 0 {
+		fatal(errors.New("-concurrency must be positive"))
+	}
+
+	mix, err := parseMix(*mixFlag)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *fake {
+		server := dagpitest.NewServer("")
+		defer server.Close()
+		server.SetLatency(*fakeLatency)
+		*baseURL, *token = server.URL, server.Token
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	b := &bench{
+		baseURL:  strings.TrimRight(*baseURL, "/"),
+		token:    *token,
+		imageURL: *imageURL,
+		httpClient: &http.Client{
+			Timeout:   *timeout,
+			Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency, Proxy: http.ProxyFromEnvironment},
+		},
+		stats: newStats(),
+	}
+	elapsed := b.run(ctx, mix, rand.New(rand.NewSource(*seed)), interval, *duration, *concurrency)
+
+	report := b.stats.report(b.baseURL, *rps, elapsed)
+	if *jsonOut {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	report.print(os.Stdout)
+}
+
+// tickInterval is the time between requests at rps, which has to leave at least a nanosecond between them
+func tickInterval(rps float64) (time.Duration, error) {
+	interval := time.Duration(float64(time.Second) / rps)
+	if !(rps > 0) || interval <= 0 {
+		return 0, fmt.Errorf("-rps must be above 0 and at most %d", time.Second)
+	}
+
+	return interval, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
+
+// weighted is a route and how often it is picked relative to the others
+type weighted struct {
+	route  routes.Route
+	weight float64
+}
+
+type mix struct {
+	routes []weighted
+	total  float64
+}
+
+// parseMix reads route=weight pairs, a route without a weight has a weight of 1
+func parseMix(value string) (*mix, error) {
+	m := &mix{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, weight := entry, 1.0
+		if i := strings.Index(entry, "="); i >= 0 {
+			var err error
+			name = entry[:i]
+			weight, err = strconv.ParseFloat(entry[i+1:], 64)
+			if err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid weight in mix entry '%s'", entry)
+			}
+		}
+
+		var group []routes.Route
+		switch name {
+		case "data":
+			group = routes.Data
+		case "image":
+			group = routes.Image
+		default:
+			route, ok := routes.Find(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown route '%s' in mix", name)
+			}
+			group = []routes.Route{route}
+		}
+
+		for _, route := range group {
+			m.routes = append(m.routes, weighted{route: route, weight: weight / float64(len(group))})
+		}
+		m.total += weight
+	}
+	if m.total <= 0 {
+		return nil, fmt.Errorf("mix has no routes")
+	}
+
+	return m, nil
+}
+
+func (m *mix) pick(rng *rand.Rand) routes.Route {
+	n := rng.Float64() * m.total
+	for _, w := range m.routes {
+		if n < w.weight {
+			return w.route
+		}
+		n -= w.weight
+	}
+
+	return m.routes[len(m.routes)-1].route
+}
+
+type bench struct {
+	baseURL    string
+	token      string
+	imageURL   string
+	httpClient *http.Client
+	stats      *stats
+}
+
+// run sends a request every interval until duration passes, then waits for the ones in flight.
+// Once ctx is done no more requests are sent and the ones in flight are cancelled.
+func (b *bench) run(ctx context.Context, m *mix, rng *rand.Rand, interval time.Duration, duration time.Duration, concurrency int) time.Duration {
+	sending, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, concurrency)
+	start := time.Now()
+
+loop:
+	for {
+		select {
+		case <-sending.Done():
+			break loop
+		case <-ticker.C:
+			route := m.pick(rng)
+			select {
+			case workers <- struct{}{}:
+			default:
+				b.stats.skip()
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-workers }()
+
+				// requests cut off by an interrupt say nothing about the API
+				result := b.call(ctx, route)
+				if !errors.Is(result.err, context.Canceled) {
+					b.stats.record(route.Path, result)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	return time.Since(start)
+}
+
+// call sends a single request and reads the whole body, like the client does
+func (b *bench) call(ctx context.Context, route routes.Route) outcome {
+	target := b.baseURL + "/" + route.Path + "/"
+	if query := route.Query(b.imageURL); len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return outcome{err: err}
+	}
+	req.Header.Add("Authorization", b.token)
+
+	start := time.Now()
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return outcome{latency: time.Since(start), err: err}
+	}
+
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	return outcome{latency: time.Since(start), status: resp.StatusCode, err: err}
+}
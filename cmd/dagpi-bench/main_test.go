@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func TestTickInterval(t *testing.T) {
+	if interval, err := tickInterval(10); err != nil || interval != 100*time.Millisecond {
+		t.Errorf("tickInterval(10) = %v, %v, want 100ms", interval, err)
+	}
+	if interval, err := tickInterval(1e9); err != nil || interval != time.Nanosecond {
+		t.Errorf("tickInterval(1e9) = %v, %v, want 1ns", interval, err)
+	}
+
+	for _, rps := range []float64{0, -1, 2e9, math.Inf(1), math.NaN()} {
+		if _, err := tickInterval(rps); err == nil {
+			t.Errorf("tickInterval(%v) = nil, want an error", rps)
+		}
+	}
+}
+
+func TestParseMix(t *testing.T) {
+	m, err := parseMix("data/joke=3, image/pixel")
+	if err != nil {
+		t.Fatalf("parseMix() = %v", err)
+	}
+	if len(m.routes) != 2 || m.total != 4 {
+		t.Fatalf("parseMix() = %d routes weighing %v, want 2 weighing 4", len(m.routes), m.total)
+	}
+
+	picked := map[string]int{}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 4000; i++ {
+		picked[m.pick(rng).Path]++
+	}
+	if jokes := picked["data/joke"]; jokes < 2700 || jokes > 3300 {
+		t.Errorf("data/joke was picked %d of 4000 times, want about 3000", jokes)
+	}
+
+	group, err := parseMix("image=2")
+	if err != nil {
+		t.Fatalf("parseMix(image=2) = %v", err)
+	}
+	for _, w := range group.routes {
+		if !w.route.IsImage() {
+			t.Errorf("image picked %s", w.route.Path)
+		}
+	}
+
+	for _, bad := range []string{"", "data/nope=1", "data/joke=-1", "data/joke=x", "data/joke=0"} {
+		if _, err := parseMix(bad); err == nil {
+			t.Errorf("parseMix(%q) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	s := newStats()
+	for i := 1; i <= 10; i++ {
+		s.record("data/joke", outcome{latency: time.Duration(i) * time.Millisecond, status: http.StatusOK})
+	}
+	s.record("image/pixel", outcome{latency: time.Millisecond, status: http.StatusTooManyRequests})
+	s.record("image/pixel", outcome{latency: time.Millisecond, status: http.StatusInternalServerError})
+	s.skip()
+
+	report := s.report("http://api", 12, 2*time.Second)
+	if len(report.Routes) != 2 || report.Skipped != 1 {
+		t.Fatalf("report has %d routes and %d skipped, want 2 and 1", len(report.Routes), report.Skipped)
+	}
+
+	joke := report.Routes[0]
+	if joke.Route != "data/joke" || joke.P50Ms != 5 || joke.P90Ms != 9 || joke.MaxMs != 10 || joke.Throughput != 5 {
+		t.Errorf("data/joke = %+v, want p50 5, p90 9, max 10 at 5 rps", joke)
+	}
+	pixel := report.Routes[1]
+	if pixel.RateLimited != 1 || pixel.Errors != 1 || pixel.ErrorRate != 0.5 {
+		t.Errorf("image/pixel = %+v, want the 429 apart from the error", pixel)
+	}
+	if report.Total.Requests != 12 || report.Total.OK != 10 {
+		t.Errorf("total = %+v, want 12 requests with 10 ok", report.Total)
+	}
+
+	var out bytes.Buffer
+	report.print(&out)
+	if !strings.Contains(out.String(), "12 requests") || !strings.Contains(out.String(), "image/pixel") {
+		t.Errorf("print() = %s, want the totals and every route", out.String())
+	}
+}
+
+func fakeBench(server *dagpitest.Server) *bench {
+	return &bench{
+		baseURL:    server.URL,
+		token:      server.Token,
+		imageURL:   server.URL + "/assets/input.png",
+		httpClient: &http.Client{},
+		stats:      newStats(),
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	m, _ := parseMix("data/joke")
+
+	b := fakeBench(server)
+	b.run(context.Background(), m, rand.New(rand.NewSource(1)), time.Millisecond, 50*time.Millisecond, 4)
+
+	report := b.stats.report(b.baseURL, 1000, 50*time.Millisecond)
+	if report.Total.Requests == 0 || report.Total.OK != report.Total.Requests {
+		t.Errorf("total = %+v, want only ok requests", report.Total)
+	}
+	if server.Requests("data/joke") != report.Total.Requests {
+		t.Errorf("the server got %d requests, the report says %d", server.Requests("data/joke"), report.Total.Requests)
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.SetLatency(time.Hour)
+	m, _ := parseMix("data/joke")
+
+	ctx, interrupt := context.WithCancel(context.Background())
+	b := fakeBench(server)
+	done := make(chan time.Duration, 1)
+	go func() {
+		done <- b.run(ctx, m, rand.New(rand.NewSource(1)), time.Millisecond, time.Hour, 1)
+	}()
+
+	for server.Requests("") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	interrupt()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("run() kept waiting on the request in flight after the interrupt")
+	}
+	if report := b.stats.report(b.baseURL, 1000, time.Second); report.Total.Requests != 0 {
+		t.Errorf("the cancelled request was recorded: %+v", report.Total)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// outcome is the result of a single request
+type outcome struct {
+	latency time.Duration
+	status  int
+	err     error
+}
+
+type routeStats struct {
+	latencies   []time.Duration
+	ok          int
+	errors      int
+	rateLimited int
+}
+
+// stats collects outcomes per route, it is safe for concurrent use
+type stats struct {
+	mu      sync.Mutex
+	routes  map[string]*routeStats
+	skipped int
+}
+
+func newStats() *stats {
+	return &stats{routes: map[string]*routeStats{}}
+}
+
+func (s *stats) record(route string, o outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.routes[route]
+	if !ok {
+		r = &routeStats{}
+		s.routes[route] = r
+	}
+
+	r.latencies = append(r.latencies, o.latency)
+	switch {
+	case o.err == nil && o.status >= 200 && o.status < 300:
+		r.ok++
+	case o.status == 429:
+		r.rateLimited++
+	default:
+		r.errors++
+	}
+}
+
+// skip counts a request that wasn't sent because every worker was busy
+func (s *stats) skip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skipped++
+}
+
+// Summary is the outcome for one route, or every route for the total
+type Summary struct {
+	Route       string  `json:"route"`
+	Requests    int     `json:"requests"`
+	OK          int     `json:"ok"`
+	Errors      int     `json:"errors"`
+	RateLimited int     `json:"rateLimited"`
+	ErrorRate   float64 `json:"errorRate"`
+	Throughput  float64 `json:"throughput"`
+	P50Ms       float64 `json:"p50Ms"`
+	P90Ms       float64 `json:"p90Ms"`
+	P99Ms       float64 `json:"p99Ms"`
+	MaxMs       float64 `json:"maxMs"`
+}
+
+// Report is everything the benchmark measured
+type Report struct {
+	BaseURL   string    `json:"baseUrl"`
+	TargetRPS float64   `json:"targetRps"`
+	ElapsedMs float64   `json:"elapsedMs"`
+	Skipped   int       `json:"skipped"`
+	Total     Summary   `json:"total"`
+	Routes    []Summary `json:"routes"`
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// percentile uses the nearest rank of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
+
+func summarize(route string, r *routeStats, elapsed time.Duration) Summary {
+	latencies := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	summary := Summary{
+		Route:       route,
+		Requests:    len(latencies),
+		OK:          r.ok,
+		Errors:      r.errors,
+		RateLimited: r.rateLimited,
+		P50Ms:       ms(percentile(latencies, 0.5)),
+		P90Ms:       ms(percentile(latencies, 0.9)),
+		P99Ms:       ms(percentile(latencies, 0.99)),
+		MaxMs:       ms(percentile(latencies, 1)),
+	}
+	if summary.Requests > 0 {
+		summary.ErrorRate = float64(r.errors) / float64(summary.Requests)
+	}
+	if elapsed > 0 {
+		summary.Throughput = float64(summary.Requests) / elapsed.Seconds()
+	}
+
+	return summary
+}
+
+func (s *stats) report(baseURL string, rps float64, elapsed time.Duration) *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &Report{BaseURL: baseURL, TargetRPS: rps, ElapsedMs: ms(elapsed), Skipped: s.skipped}
+
+	var names []string
+	for name := range s.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	total := &routeStats{}
+	for _, name := range names {
+		r := s.routes[name]
+		report.Routes = append(report.Routes, summarize(name, r, elapsed))
+
+		total.latencies = append(total.latencies, r.latencies...)
+		total.ok += r.ok
+		total.errors += r.errors
+		total.rateLimited += r.rateLimited
+	}
+	report.Total = summarize("total", total, elapsed)
+
+	return report
+}
+
+// print writes the report as a table
+func (r *Report) print(w io.Writer) {
+	fmt.Fprintf(w, "%s: %d requests in %.1fs, target %.1f rps, %d skipped with every worker busy\n\n",
+		r.BaseURL, r.Total.Requests, r.ElapsedMs/1000, r.TargetRPS, r.Skipped)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "route\trequests\tok\terrors\t429\terror %\trps\tp50 ms\tp90 ms\tp99 ms\tmax ms\t")
+	for _, summary := range append(r.Routes, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n",
+			summary.Route, summary.Requests, summary.OK, summary.Errors, summary.RateLimited, summary.ErrorRate*100,
+			summary.Throughput, summary.P50Ms, summary.P90Ms, summary.P99Ms, summary.MaxMs)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,69 @@
+// Command dagpi-contract runs the contract suite against a Dagpi compatible API and prints a json report.
+// It exits with status 1 when any route drifted from what the client expects and 2 when the run failed,
+// including when -routes names an unknown route or no route was left to check.
+//
+//	dagpi-contract -token $DAGPI_TOKEN -out report.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/contract"
+)
+
+func main() {
+	baseURL := flag.String("base", dagpi.DefaultBaseURL, "base url of the API")
+	token := flag.String("token", os.Getenv("DAGPI_TOKEN"), "API token, defaults to $DAGPI_TOKEN")
+	routes := flag.String("routes", "", "comma separated routes to check, like data/joke,image/pixel. Empty checks every route")
+	dataOnly := flag.Bool("data-only", false, "only check the data routes")
+	imageURL := flag.String("image-url", contract.DefaultImageURL, "input image for image routes")
+	rpm := flag.Int("rpm", 60, "requests per minute, 0 for no limit")
+	out := flag.String("out", "", "write the report to a file instead of stdout")
+	flag.Parse()
+
+	opts := contract.Options{ImageURL: *imageURL, SkipImages: *dataOnly}
+	if *routes != "" {
+		opts.Routes = strings.Split(*routes, ",")
+	}
+	if *rpm > 0 {
+		opts.RateLimiter = dagpi.NewLimiter(*rpm, time.Minute)
+	}
+
+	report, err := contract.Run(context.Background(), *baseURL, *token, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	data, err := report.JSON()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *out != "" {
+		err = ioutil.WriteFile(*out, data, 0644)
+	} else {
+		_, err = os.Stdout.Write(append(data, '\n'))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// a filter that leaves nothing to check mustn't pass as a clean run
+	if len(report.Results) == 0 {
+		fmt.Fprintln(os.Stderr, "no routes were checked")
+		os.Exit(2)
+	}
+	if !report.OK() {
+		fmt.Fprintf(os.Stderr, "%d of %d routes drifted\n", report.Failed, report.Passed+report.Failed)
+		os.Exit(1)
+	}
+}
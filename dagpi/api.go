@@ -0,0 +1,96 @@
+package dagpi
+
+// DagpiAPI is every Data and Image call of the Client, so code using the API can be handed a mock in tests.
+// dagpimock.Mock implements it.
+type DagpiAPI interface {
+	//region Data API calls
+
+	WTP() (interface{}, error)
+	Roast() (interface{}, error)
+	Joke() (interface{}, error)
+	Fact() (interface{}, error)
+	Eightball() (interface{}, error)
+	Yomama() (interface{}, error)
+	RandomWaifu() (interface{}, error)
+	Waifu(waifuName string) (interface{}, error)
+	PickupLine() (interface{}, error)
+	HeadLine() (interface{}, error)
+	GTL() (interface{}, error)
+	Flag() (interface{}, error)
+	Captcha() (interface{}, error)
+	Typeracer() (interface{}, error)
+
+	//endregion
+
+	//region Image API calls
+
+	Pixelate(url string) ([]byte, error)
+	Mirror(url string) ([]byte, error)
+	FlipImage(url string) ([]byte, error)
+	Colors(url string) ([]byte, error)
+	America(url string) ([]byte, error)
+	Communism(url string) ([]byte, error)
+	Triggered(url string) ([]byte, error)
+	ExpandImage(url string) ([]byte, error)
+	Wasted(url string) ([]byte, error)
+	Sketch(url string) ([]byte, error)
+	SpinImage(url string) ([]byte, error)
+	PetPet(url string) ([]byte, error)
+	Bonk(url string) ([]byte, error)
+	Bomb(url string) ([]byte, error)
+	Shake(url string) ([]byte, error)
+	Invert(url string) ([]byte, error)
+	Sobel(url string) ([]byte, error)
+	Hog(url string) ([]byte, error)
+	Triangle(url string) ([]byte, error)
+	Blur(url string) ([]byte, error)
+	RGB(url string) ([]byte, error)
+	Angel(url string) ([]byte, error)
+	Satan(url string) ([]byte, error)
+	Delete(url string) ([]byte, error)
+	Fedora(url string) ([]byte, error)
+	Hitler(url string) ([]byte, error)
+	Lego(url string) ([]byte, error)
+	Wanted(url string) ([]byte, error)
+	Stringify(url string) ([]byte, error)
+	Burn(url string) ([]byte, error)
+	Earth(url string) ([]byte, error)
+	Freeze(url string) ([]byte, error)
+	Ground(url string) ([]byte, error)
+	Mosiac(url string) ([]byte, error)
+	Sithlord(url string) ([]byte, error)
+	Jail(url string) ([]byte, error)
+	Shatter(url string) ([]byte, error)
+	Pride(url string, flag string) ([]byte, error)
+	Trash(url string) ([]byte, error)
+	Deepfry(url string) ([]byte, error)
+	Ascii(url string) ([]byte, error)
+	Charcoal(url string) ([]byte, error)
+	Posterize(url string) ([]byte, error)
+	Sepia(url string) ([]byte, error)
+	Swirl(url string) ([]byte, error)
+	Paint(url string) ([]byte, error)
+	Night(url string) ([]byte, error)
+	Rainbow(url string) ([]byte, error)
+	Magik(url string) ([]byte, error)
+	FivegOneg(url1 string, url2 string) ([]byte, error)
+	WhyAreYouGay(url1 string, url2 string) ([]byte, error)
+	Slap(url1 string, url2 string) ([]byte, error)
+	Obama(url1 string, url2 string) ([]byte, error)
+	Tweet(url string, username string, text string) ([]byte, error)
+	YouTubeComment(url string, username string, text string, darkMode bool) ([]byte, error)
+	Discord(url string, username string, text string, darkMode bool) ([]byte, error)
+	Retromeme(url string, topText string, bottomText string) ([]byte, error)
+	Motivational(url string, topText string, bottomText string) ([]byte, error)
+	Modernmeme(url string, text string) ([]byte, error)
+	Elmo(url string) ([]byte, error)
+	TvStatic(url string) ([]byte, error)
+	Rain(url string) ([]byte, error)
+	Glitch(url string) ([]byte, error)
+	GlitchStatic(url string) ([]byte, error)
+	Album(url string) ([]byte, error)
+
+	//endregion
+}
+
+var _ DagpiAPI = (*Client)(nil)
@@ -0,0 +1,136 @@
+package dagpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is the number of workers used when BatchOptions.Concurrency is not set
+const DefaultBatchConcurrency = 4
+
+// ErrSkipped is the error of batch jobs that never ran because the batch was stopped
+var ErrSkipped = errors.New("batch job skipped")
+
+// BatchJob is a single effect to apply to a single image
+type BatchJob struct {
+	Effect Effect
+	URL    string
+}
+
+// BatchResult is the outcome of a BatchJob
+type BatchResult struct {
+	Job      BatchJob
+	Image    []byte
+	Err      error
+	Duration time.Duration
+}
+
+// BatchOptions configure Client.Batch
+type BatchOptions struct {
+	// Concurrency is the number of jobs running at once, defaults to DefaultBatchConcurrency
+	Concurrency int
+
+	// StopOnError stops handing out jobs after the first failure. Jobs already running are left to finish.
+	StopOnError bool
+
+	// Progress is called after every finished job. Calls never overlap so it doesn't need to be thread safe.
+	Progress func(done int, total int, result BatchResult)
+}
+
+// BatchError is returned by Batch when StopOnError is set and a job failed
+type BatchError struct {
+	Index int
+	Job   BatchJob
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch job %d (%s %s) failed: %v", e.Index, e.Job.Effect, e.Job.URL, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Batch runs many jobs with a bounded pool of workers. Every request still goes through the client's RateLimiter.
+// Results are in the same order as jobs, each with its own error. The returned error is only set when the context
+// was cancelled or StopOnError stopped the batch, jobs that never ran have ErrSkipped as their error.
+func (c *Client) Batch(ctx context.Context, jobs []BatchJob, opts BatchOptions) ([]BatchResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > len(jobs) {
+		concurrency = len(jobs)
+	}
+
+	results := make([]BatchResult, len(jobs))
+	for i, job := range jobs {
+		results[i] = BatchResult{Job: job, Err: ErrSkipped}
+	}
+
+	var (
+		mu       sync.Mutex
+		done     int
+		firstErr *BatchError
+		wg       sync.WaitGroup
+		stop     = make(chan struct{})
+	)
+
+	indexes := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				// the feed loop can still hand out a job right after the batch was stopped
+				mu.Lock()
+				stopped := firstErr != nil
+				mu.Unlock()
+				if stopped || ctx.Err() != nil {
+					continue
+				}
+
+				job := jobs[i]
+				start := c.clock().Now()
+				image, err := c.ApplyContext(ctx, job.Effect, job.URL)
+				result := BatchResult{Job: job, Image: image, Err: err, Duration: c.clock().Now().Sub(start)}
+
+				mu.Lock()
+				results[i] = result
+				done++
+				if err != nil && opts.StopOnError && firstErr == nil {
+					firstErr = &BatchError{Index: i, Job: job, Err: err}
+					close(stop)
+				}
+				if opts.Progress != nil {
+					opts.Progress(done, len(jobs), result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range jobs {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		case <-stop:
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+
+	return results, ctx.Err()
+}
@@ -0,0 +1,137 @@
+package dagpi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// gateTransport holds requests to paths containing match until release is closed
+type gateTransport struct {
+	match   string
+	release chan struct{}
+}
+
+func (g *gateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.Path, g.match) {
+		<-g.release
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func batchJobs(server *dagpitest.Server, effects ...dagpi.Effect) []dagpi.BatchJob {
+	var jobs []dagpi.BatchJob
+	for _, effect := range effects {
+		jobs = append(jobs, dagpi.BatchJob{Effect: effect, URL: server.URL + "/assets/input.png"})
+	}
+
+	return jobs
+}
+
+func TestBatch(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.Fail("image/sepia", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "broken"})
+
+	jobs := batchJobs(server, dagpi.EffectPixelate, dagpi.EffectSepia, dagpi.EffectWanted, dagpi.EffectInvert, dagpi.EffectBlur)
+	calls := 0
+	results, err := server.Client().Batch(context.Background(), jobs, dagpi.BatchOptions{
+		Concurrency: 2,
+		Progress: func(done int, total int, result dagpi.BatchResult) {
+			calls++
+			if done != calls || total != len(jobs) {
+				t.Errorf("Progress(%d, %d), want (%d, %d)", done, total, calls, len(jobs))
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Batch() = %v, a failed job shouldn't fail the batch without StopOnError", err)
+	}
+
+	if calls != len(jobs) {
+		t.Errorf("Progress was called %d times, want %d", calls, len(jobs))
+	}
+	for i, result := range results {
+		if result.Job != jobs[i] {
+			t.Errorf("result %d is for %s, want %s", i, result.Job.Effect, jobs[i].Effect)
+		}
+		if i == 1 {
+			if result.Err == nil {
+				t.Error("the sepia job didn't fail")
+			}
+			continue
+		}
+		if result.Err != nil || len(result.Image) == 0 {
+			t.Errorf("job %d = %v, want an image", i, result.Err)
+		}
+	}
+}
+
+func TestBatchStopOnError(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.Fail("image/pixel", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "broken"})
+
+	// sepia is held until pixel has failed, so it is running when the batch stops
+	gate := &gateTransport{match: "/image/sepia", release: make(chan struct{})}
+	client := server.Client()
+	client.HTTPClient = &http.Client{Transport: gate}
+
+	jobs := batchJobs(server, dagpi.EffectPixelate, dagpi.EffectSepia, dagpi.EffectWanted, dagpi.EffectInvert)
+	results, err := client.Batch(context.Background(), jobs, dagpi.BatchOptions{
+		Concurrency: 2,
+		StopOnError: true,
+		Progress: func(done int, total int, result dagpi.BatchResult) {
+			if result.Job.Effect == dagpi.EffectPixelate {
+				close(gate.release)
+			}
+		},
+	})
+
+	var batchErr *dagpi.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 0 {
+		t.Fatalf("Batch() = %v, want a BatchError for job 0", err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("the running sepia job = %v, want it to finish", results[1].Err)
+	}
+	for _, i := range []int{2, 3} {
+		if results[i].Err != dagpi.ErrSkipped {
+			t.Errorf("job %d = %v, want ErrSkipped", i, results[i].Err)
+		}
+	}
+	if server.Requests("image/wanted")+server.Requests("image/invert") != 0 {
+		t.Error("jobs were started after the batch stopped")
+	}
+}
+
+func TestBatchCancelled(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := batchJobs(server, dagpi.EffectPixelate, dagpi.EffectSepia, dagpi.EffectWanted)
+	results, err := server.Client().Batch(ctx, jobs, dagpi.BatchOptions{
+		Concurrency: 1,
+		Progress: func(done int, total int, result dagpi.BatchResult) {
+			cancel()
+		},
+	})
+	if err != context.Canceled {
+		t.Fatalf("Batch() = %v, want context.Canceled", err)
+	}
+	if results[0].Err != nil {
+		t.Errorf("job 0 = %v, want it to finish", results[0].Err)
+	}
+	for _, i := range []int{1, 2} {
+		if results[i].Err != dagpi.ErrSkipped {
+			t.Errorf("job %d = %v, want ErrSkipped", i, results[i].Err)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package dagpi
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores image responses so deterministic effects on the same image aren't rendered twice.
+// Only image manipulation calls are cached, data calls like Joke or WTP are random and always go to the API.
+type Cache interface {
+	// Get returns the value for key if it is present and not expired
+	Get(key string) ([]byte, bool)
+	// Set stores value for key, a ttl of zero never expires
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheStats are the counters kept by the caches in this package
+type CacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Entries   int
+	Bytes     int64
+}
+
+// image routes whose output changes every call even with the same input
+var randomEffects = map[Effect]bool{
+	EffectGlitch:       true,
+	EffectGlitchStatic: true,
+	EffectTvStatic:     true,
+}
+
+// cacheKey is the route plus its sorted params so the same call always maps to the same key.
+// An empty key means the call must not be cached.
+func cacheKey(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+
+	route := strings.Trim(u.Path, "/")
+	if !strings.HasPrefix(route, "image/") || randomEffects[Effect(strings.TrimPrefix(route, "image/"))] {
+		return ""
+	}
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, key+"="+value)
+		}
+	}
+
+	return route + "?" + strings.Join(params, "&")
+}
+
+//region Memory cache
+
+// MemoryCache is an in memory least recently used Cache limited by the total size of its values
+type MemoryCache struct {
+	// Clock decides when entries expire, defaults to SystemClock
+	Clock Clock
+
+	mu       sync.Mutex
+	maxBytes int64
+	entries  map[string]*list.Element
+	order    *list.List
+	stats    CacheStats
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache that evicts the least recently used values once it holds more than maxBytes
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxBytes: maxBytes,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get returns the value for key if it is present and not expired
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		m.stats.Misses++
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && clockOrSystem(m.Clock).Now().After(entry.expires) {
+		m.remove(element)
+		m.stats.Misses++
+		return nil, false
+	}
+
+	m.order.MoveToFront(element)
+	m.stats.Hits++
+
+	return entry.value, true
+}
+
+// Set stores value for key, a ttl of zero never expires
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	if int64(len(value)) > m.maxBytes {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = clockOrSystem(m.Clock).Now().Add(ttl)
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	m.stats.Entries++
+	m.stats.Bytes += int64(len(value))
+
+	for m.stats.Bytes > m.maxBytes {
+		m.remove(m.order.Back())
+		m.stats.Evictions++
+	}
+}
+
+// must hold m.mu
+func (m *MemoryCache) remove(element *list.Element) {
+	entry := m.order.Remove(element).(*memoryEntry)
+	delete(m.entries, entry.key)
+	m.stats.Entries--
+	m.stats.Bytes -= int64(len(entry.value))
+}
+
+// Stats returns the hit, miss and eviction counters along with the current size
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+//endregion
+
+//region Disk cache
+
+// DiskCache is a Cache that keeps one file per value in a directory. When the directory grows past
+// its limit the least recently used files are deleted.
+type DiskCache struct {
+	// Clock decides when entries expire, defaults to SystemClock
+	Clock Clock
+
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	stats    CacheStats
+}
+
+// NewDiskCache creates a DiskCache in dir, creating it if needed. Files already in dir count towards maxBytes.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskCache{dir: dir, maxBytes: maxBytes}
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		d.stats.Entries++
+		d.stats.Bytes += file.Size()
+	}
+
+	return d, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".cache")
+}
+
+func (d *DiskCache) files() ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".cache") {
+			files = append(files, info)
+		}
+	}
+
+	return files, nil
+}
+
+// Get returns the value for key if it is present and not expired
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.path(key)
+	data, err := ioutil.ReadFile(path)
+	if err != nil || len(data) < 8 {
+		d.stats.Misses++
+		return nil, false
+	}
+
+	// every file starts with its expiry in unix nanoseconds, zero never expires
+	expires := int64(binary.BigEndian.Uint64(data[:8]))
+	if expires != 0 && clockOrSystem(d.Clock).Now().UnixNano() > expires {
+		d.removeFile(path, int64(len(data)))
+		d.stats.Misses++
+		return nil, false
+	}
+
+	// the modification time only orders files for eviction, so it stays on the real clock like the file system's own
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	d.stats.Hits++
+
+	return data[8:], true
+}
+
+// Set stores value for key, a ttl of zero never expires
+func (d *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	size := int64(len(value) + 8)
+	if size > d.maxBytes {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.path(key)
+	if info, err := os.Stat(path); err == nil {
+		d.removeFile(path, info.Size())
+	}
+
+	data := make([]byte, size)
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(data[:8], uint64(clockOrSystem(d.Clock).Now().Add(ttl).UnixNano()))
+	}
+	copy(data[8:], value)
+
+	// write to a temp file first so a crash never leaves half a value behind
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	d.stats.Entries++
+	d.stats.Bytes += size
+
+	if d.stats.Bytes > d.maxBytes {
+		d.evict()
+	}
+}
+
+// must hold d.mu
+func (d *DiskCache) removeFile(path string, size int64) {
+	if os.Remove(path) == nil {
+		d.stats.Entries--
+		d.stats.Bytes -= size
+	}
+}
+
+// deletes the least recently used files until the cache fits, must hold d.mu
+func (d *DiskCache) evict() {
+	files, err := d.files()
+	if err != nil {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, file := range files {
+		if d.stats.Bytes <= d.maxBytes {
+			break
+		}
+		d.removeFile(filepath.Join(d.dir, file.Name()), file.Size())
+		d.stats.Evictions++
+	}
+}
+
+// Stats returns the hit, miss and eviction counters along with the current size
+func (d *DiskCache) Stats() CacheStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.stats
+}
+
+//endregion
@@ -0,0 +1,165 @@
+package dagpi_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func TestMemoryCacheLRU(t *testing.T) {
+	cache := dagpi.NewMemoryCache(10)
+	cache.Set("a", []byte("aaaa"), 0)
+	cache.Set("b", []byte("bbbb"), 0)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+
+	// b is the least recently used so it goes first
+	cache.Set("c", []byte("cccc"), 0)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b wasn't evicted")
+	}
+	if value, ok := cache.Get("a"); !ok || string(value) != "aaaa" {
+		t.Errorf("Get(a) = %q, %v, want aaaa", value, ok)
+	}
+
+	cache.Set("huge", make([]byte, 11), 0)
+	if _, ok := cache.Get("huge"); ok {
+		t.Error("a value larger than the cache was stored")
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Bytes != 8 || stats.Evictions != 1 {
+		t.Errorf("Stats() = %+v, want 2 entries, 8 bytes and 1 eviction", stats)
+	}
+	if stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v, want 2 hits and 2 misses", stats)
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	cache := dagpi.NewMemoryCache(1 << 10)
+	cache.Clock = clock
+
+	cache.Set("short", []byte("x"), time.Minute)
+	cache.Set("forever", []byte("y"), 0)
+
+	clock.Advance(time.Minute)
+	if _, ok := cache.Get("short"); !ok {
+		t.Error("the entry expired at its ttl instead of after it")
+	}
+	clock.Advance(time.Second)
+	if _, ok := cache.Get("short"); ok {
+		t.Error("the entry is still there after its ttl")
+	}
+	clock.Advance(24 * time.Hour)
+	if _, ok := cache.Get("forever"); !ok {
+		t.Error("an entry without a ttl expired")
+	}
+	if stats := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("Stats().Entries = %d, want the expired entry removed", stats.Entries)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	clock := dagpitest.NewClock(time.Time{})
+	cache, err := dagpi.NewDiskCache(dir, 1<<10)
+	if err != nil {
+		t.Fatalf("NewDiskCache() = %v", err)
+	}
+	cache.Clock = clock
+
+	cache.Set("a", []byte("value a"), time.Minute)
+	cache.Set("b", []byte("value b"), 0)
+	if value, ok := cache.Get("a"); !ok || string(value) != "value a" {
+		t.Fatalf("Get(a) = %q, %v, want value a", value, ok)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("a is still there after its ttl")
+	}
+
+	// a new cache over the same directory picks up what is already there
+	reopened, err := dagpi.NewDiskCache(dir, 1<<10)
+	if err != nil {
+		t.Fatalf("NewDiskCache() = %v", err)
+	}
+	if value, ok := reopened.Get("b"); !ok || string(value) != "value b" {
+		t.Errorf("Get(b) after reopening = %q, %v, want value b", value, ok)
+	}
+	if stats := reopened.Stats(); stats.Entries != 1 || stats.Bytes != int64(len("value b")+8) {
+		t.Errorf("Stats() after reopening = %+v, want the one file left", stats)
+	}
+}
+
+func TestDiskCacheEvicts(t *testing.T) {
+	cache, err := dagpi.NewDiskCache(t.TempDir(), 100)
+	if err != nil {
+		t.Fatalf("NewDiskCache() = %v", err)
+	}
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, bytes.Repeat([]byte(key), 30), 0)
+	}
+
+	stats := cache.Stats()
+	if stats.Bytes > 100 {
+		t.Errorf("the cache holds %d bytes, want at most 100", stats.Bytes)
+	}
+	if stats.Evictions == 0 {
+		t.Error("nothing was evicted")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	clock := dagpitest.NewClock(time.Time{})
+	cache := dagpi.NewMemoryCache(1 << 20)
+	cache.Clock = clock
+
+	client := server.Client()
+	client.Cache = cache
+	client.CacheTTL = time.Hour
+
+	input := server.URL + "/assets/input.png"
+	first, err := client.Pixelate(input)
+	if err != nil {
+		t.Fatalf("Pixelate() = %v", err)
+	}
+	second, err := client.Pixelate(input)
+	if err != nil {
+		t.Fatalf("Pixelate() = %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("the cached image differs from the original")
+	}
+	if server.Requests("image/pixel") != 1 {
+		t.Errorf("the API got %d requests, want the second call served from the cache", server.Requests("image/pixel"))
+	}
+
+	clock.Advance(2 * time.Hour)
+	if _, err = client.Pixelate(input); err != nil {
+		t.Fatalf("Pixelate() = %v", err)
+	}
+	if server.Requests("image/pixel") != 2 {
+		t.Error("an expired image was served from the cache")
+	}
+
+	// glitch is random so every call has to reach the API
+	for i := 0; i < 2; i++ {
+		if _, err = client.Glitch(input); err != nil {
+			t.Fatalf("Glitch() = %v", err)
+		}
+	}
+	if server.Requests("image/glitch") != 2 {
+		t.Errorf("the API got %d glitch requests, want 2", server.Requests("image/glitch"))
+	}
+}
@@ -0,0 +1,330 @@
+// Package cassette records the client's traffic with the API once and replays it later without network access.
+// A cassette is a json file of request and response pairs. The token is never written and image bodies are
+// stored as files next to the cassette.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned in strict replay for requests that aren't in the cassette
+var ErrNoInteraction = errors.New("request is not in the cassette")
+
+// Redacted replaces the value of headers that must not be written to a cassette
+const Redacted = "REDACTED"
+
+// Mode is whether a Recorder records new interactions or replays saved ones
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette
+	ModeReplay Mode = iota
+	// ModeRecord sends requests on and records them, Save writes the cassette
+	ModeRecord
+)
+
+// Request is the recorded part of a request
+type Request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Response is the recorded part of a response. Text bodies are kept in Body and the rest in BodyFile,
+// relative to the cassette.
+type Response struct {
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	BodyFile string            `json:"bodyFile,omitempty"`
+}
+
+// Interaction is a request and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	body []byte
+	used bool
+}
+
+// Matcher reports whether a recorded request can answer req
+type Matcher func(req *http.Request, recorded Request) bool
+
+// MatchRoute matches requests with the same method and path, ignoring params
+func MatchRoute(req *http.Request, recorded Request) bool {
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	return req.Method == recorded.Method && strings.Trim(req.URL.Path, "/") == strings.Trim(u.Path, "/")
+}
+
+// MatchParams matches requests with the same method, path and params, except the ignored ones
+func MatchParams(ignored ...string) Matcher {
+	return func(req *http.Request, recorded Request) bool {
+		if !MatchRoute(req, recorded) {
+			return false
+		}
+
+		u, _ := url.Parse(recorded.URL)
+		want, got := u.Query(), req.URL.Query()
+		for _, name := range ignored {
+			want.Del(name)
+			got.Del(name)
+		}
+
+		return want.Encode() == got.Encode()
+	}
+}
+
+// Recorder is an http.RoundTripper that records or replays a cassette.
+// Use it as the Transport of dagpi.Client.HTTPClient.
+type Recorder struct {
+	// Mode the recorder was created with
+	Mode Mode
+
+	// Strict makes replay fail with ErrNoInteraction for requests that aren't in the cassette.
+	// Otherwise they are sent on with Transport.
+	Strict bool
+
+	// Match decides which recorded request answers a request, defaults to MatchParams()
+	Match Matcher
+
+	// RedactHeaders are request headers whose values are never written. Authorization is always redacted.
+	RedactHeaders []string
+
+	// Transport sends requests on when recording, defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	path         string
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// New creates a Recorder for the cassette at path, like "testdata/cassettes/jokes.json".
+// Replaying loads the cassette, recording starts an empty one.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Mode: mode, path: path}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &r.interactions)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %w", path, err)
+	}
+
+	for _, interaction := range r.interactions {
+		interaction.body = []byte(interaction.Response.Body)
+		if interaction.Response.BodyFile != "" {
+			interaction.body, err = ioutil.ReadFile(filepath.Join(filepath.Dir(path), filepath.FromSlash(interaction.Response.BodyFile)))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// HTTPClient returns an http.Client using the recorder
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns how many interactions the cassette holds
+func (r *Recorder) Interactions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.interactions)
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport == nil {
+		return http.DefaultTransport
+	}
+
+	return r.Transport
+}
+
+// RoundTrip records or replays a single request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.Mode == ModeRecord {
+		return r.record(req)
+	}
+
+	interaction := r.find(req)
+	if interaction == nil {
+		if r.Strict {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+		}
+		return r.transport().RoundTrip(req)
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+		StatusCode:    interaction.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewReader(interaction.body)),
+		ContentLength: int64(len(interaction.body)),
+		Request:       req,
+	}
+	for name, value := range interaction.Response.Headers {
+		resp.Header.Set(name, value)
+	}
+
+	return resp, nil
+}
+
+// find returns the first unused interaction matching req, or the last used one so repeated requests keep getting an answer
+func (r *Recorder) find(req *http.Request) *Interaction {
+	match := r.Match
+	if match == nil {
+		match = MatchParams()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *Interaction
+	for _, interaction := range r.interactions {
+		if !match(req, interaction.Request) {
+			continue
+		}
+		if !interaction.used {
+			interaction.used = true
+			return interaction
+		}
+		last = interaction
+	}
+
+	return last
+}
+
+func (r *Recorder) redacted(name string) bool {
+	if strings.EqualFold(name, "Authorization") {
+		return true
+	}
+	for _, header := range r.RedactHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	interaction := &Interaction{
+		Request: Request{Method: req.Method, URL: req.URL.String(), Headers: map[string]string{}},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: map[string]string{},
+		},
+		body: body,
+	}
+	for name := range req.Header {
+		interaction.Request.Headers[name] = req.Header.Get(name)
+		if r.redacted(name) {
+			interaction.Request.Headers[name] = Redacted
+		}
+	}
+	for name := range resp.Header {
+		interaction.Response.Headers[name] = resp.Header.Get(name)
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// isText reports whether a body with contentType can be kept in the cassette itself
+func isText(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/json"
+}
+
+// Save writes the recorded cassette along with a directory of its image bodies, named after the cassette
+func (r *Recorder) Save() error {
+	if r.Mode != ModeRecord {
+		return errors.New("only a recording cassette can be saved")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := filepath.Dir(r.path)
+	bodies := strings.TrimSuffix(filepath.Base(r.path), filepath.Ext(r.path))
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	for i, interaction := range r.interactions {
+		contentType := interaction.Response.Headers["Content-Type"]
+		if isText(contentType) || len(interaction.body) == 0 {
+			interaction.Response.Body = string(interaction.body)
+			continue
+		}
+
+		ext := ".bin"
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && strings.HasPrefix(mediaType, "image/") {
+			ext = "." + strings.TrimPrefix(mediaType, "image/")
+		}
+
+		file := filepath.Join(bodies, strconv.Itoa(i)+ext)
+		err = os.MkdirAll(filepath.Join(dir, bodies), 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, file), interaction.body, 0644)
+		if err != nil {
+			return err
+		}
+		interaction.Response.BodyFile = filepath.ToSlash(file)
+	}
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, data, 0644)
+}
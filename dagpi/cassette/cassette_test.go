@@ -0,0 +1,147 @@
+package cassette_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/cassette"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// recorded records a joke and a pixelated image from a fake server that is closed again before returning,
+// so replaying can't reach it
+func recorded(t *testing.T) (path string, input string, joke interface{}, pixel []byte) {
+	t.Helper()
+
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	path = filepath.Join(t.TempDir(), "cassettes", "client.json")
+	recorder, err := cassette.New(path, cassette.ModeRecord)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	client := server.Client()
+	client.HTTPClient = recorder.HTTPClient()
+
+	input = server.URL + "/assets/input.png"
+	if joke, err = client.Joke(); err != nil {
+		t.Fatalf("Joke() = %v", err)
+	}
+	if pixel, err = client.Pixelate(input); err != nil {
+		t.Fatalf("Pixelate() = %v", err)
+	}
+	if err = recorder.Save(); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	return path, input, joke, pixel
+}
+
+func replay(t *testing.T, path string) (*cassette.Recorder, *dagpi.Client) {
+	t.Helper()
+
+	recorder, err := cassette.New(path, cassette.ModeReplay)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	recorder.Strict = true
+
+	return recorder, &dagpi.Client{Auth: "another-token", BaseURL: "http://dagpi.invalid", HTTPClient: recorder.HTTPClient()}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path, input, joke, pixel := recorded(t)
+
+	recorder, _ := replay(t, path)
+	if recorder.Interactions() != 2 {
+		t.Fatalf("Interactions() = %d, want 2", recorder.Interactions())
+	}
+
+	// the base url was the fake server's when recording
+	client := &dagpi.Client{Auth: "another-token", BaseURL: input[:strings.Index(input, "/assets")], HTTPClient: recorder.HTTPClient()}
+	got, err := client.Joke()
+	if err != nil {
+		t.Fatalf("replayed Joke() = %v", err)
+	}
+	if got.(map[string]interface{})["joke"] != joke.(map[string]interface{})["joke"] {
+		t.Errorf("replayed Joke() = %v, want %v", got, joke)
+	}
+	image, err := client.Pixelate(input)
+	if err != nil {
+		t.Fatalf("replayed Pixelate() = %v", err)
+	}
+	if !bytes.Equal(image, pixel) {
+		t.Error("replayed Pixelate() answered a different image")
+	}
+
+	// repeated requests keep getting the last answer
+	if _, err = client.Joke(); err != nil {
+		t.Errorf("Joke() replayed twice = %v", err)
+	}
+}
+
+func TestSaveRedactsAndSplitsBodies(t *testing.T) {
+	path, _, _, pixel := recorded(t)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading the cassette: %v", err)
+	}
+	if strings.Contains(string(data), dagpitest.DefaultToken) {
+		t.Error("the cassette holds the token")
+	}
+	if !strings.Contains(string(data), cassette.Redacted) {
+		t.Error("the cassette has no redacted Authorization header")
+	}
+
+	body, err := ioutil.ReadFile(filepath.Join(filepath.Dir(path), "client", "1.png"))
+	if err != nil || !bytes.Equal(body, pixel) {
+		t.Errorf("the image body wasn't saved next to the cassette: %v", err)
+	}
+}
+
+func TestStrictReplay(t *testing.T) {
+	path, _, _, _ := recorded(t)
+
+	_, client := replay(t, path)
+	if _, err := client.Fact(); !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("Fact() = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	path, input, _, pixel := recorded(t)
+	base := input[:strings.Index(input, "/assets")]
+	other := base + "/assets/other.png"
+
+	recorder, _ := replay(t, path)
+	client := &dagpi.Client{Auth: "another-token", BaseURL: base, HTTPClient: recorder.HTTPClient()}
+	if _, err := client.Pixelate(other); !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("Pixelate() of another image = %v, want ErrNoInteraction matching params", err)
+	}
+
+	recorder.Match = cassette.MatchParams("url")
+	if image, err := client.Pixelate(other); err != nil || !bytes.Equal(image, pixel) {
+		t.Errorf("Pixelate() ignoring the url param = %v, want the recorded image", err)
+	}
+
+	recorder.Match = cassette.MatchRoute
+	if image, err := client.Pixelate(other); err != nil || !bytes.Equal(image, pixel) {
+		t.Errorf("Pixelate() matching the route = %v, want the recorded image", err)
+	}
+}
+
+func TestSaveOnlyWhenRecording(t *testing.T) {
+	path, _, _, _ := recorded(t)
+
+	recorder, _ := replay(t, path)
+	if err := recorder.Save(); err == nil {
+		t.Error("Save() while replaying = nil, want an error")
+	}
+}
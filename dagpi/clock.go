@@ -0,0 +1,113 @@
+package dagpi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for it to pass. Rate limiting, cache expiry, the circuit breaker, latency
+// budgets and timings all go through one, so tests can swap in dagpitest.Clock instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a Timer that fires once d has passed
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event from a Clock, like time.Timer
+type Timer interface {
+	// C receives the time when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it reports false if it already fired or was stopped
+	Stop() bool
+}
+
+// SystemClock is the Clock used when none is set, it uses the time package
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// clockOrSystem returns clock, or SystemClock when it is nil
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+
+	return clock
+}
+
+// withTimeout is context.WithTimeout on clock. The context only reports the parent's deadline, since a fake clock's
+// time means nothing to the network code reading it.
+func withTimeout(parent context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	clock = clockOrSystem(clock)
+	if clock == SystemClock {
+		return context.WithTimeout(parent, d)
+	}
+
+	ctx := &timeoutContext{Context: parent, done: make(chan struct{})}
+	timer := clock.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			ctx.stop(context.DeadlineExceeded)
+		case <-parent.Done():
+			timer.Stop()
+			ctx.stop(parent.Err())
+		case <-ctx.done:
+			timer.Stop()
+		}
+	}()
+
+	return ctx, func() { ctx.stop(context.Canceled) }
+}
+
+// timeoutContext is done once its clock's timer fires. It doesn't cancel through the parent's cancel chain,
+// so code asking for the cause of the cancellation gets context.DeadlineExceeded like with context.WithTimeout.
+type timeoutContext struct {
+	context.Context
+
+	mu   sync.Mutex
+	done chan struct{}
+	err  error
+}
+
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *timeoutContext) stop(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
@@ -0,0 +1,106 @@
+package dagpi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// flightGroup shares the result of one in flight request between every identical request made while it runs
+type flightGroup struct {
+	mu        sync.Mutex
+	calls     map[string]*flight
+	coalesced int64
+}
+
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	image   *Image
+	err     error
+}
+
+// do runs fn once per key at a time, callers arriving while it runs wait for the same result.
+// fn gets a context of its own since it is shared. Every caller, the first one included, stops waiting
+// when its own context ends, and fn's context is cancelled once no caller is left waiting.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*Image, error)) (*Image, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flight{}
+	}
+	f, ok := g.calls[key]
+	if ok {
+		f.waiters++
+		g.mu.Unlock()
+		atomic.AddInt64(&g.coalesced, 1)
+	} else {
+		flightCtx, cancel := context.WithCancel(context.Background())
+		f = &flight{done: make(chan struct{}), cancel: cancel, waiters: 1}
+		g.calls[key] = f
+		g.mu.Unlock()
+
+		go g.run(flightCtx, key, f, fn)
+	}
+
+	select {
+	case <-f.done:
+		return f.image, f.err
+	case <-ctx.Done():
+		g.leave(key, f)
+		return nil, ctx.Err()
+	}
+}
+
+// run calls fn for a flight and wakes its waiters, a panic in fn is returned to them as an error
+func (g *flightGroup) run(ctx context.Context, key string, f *flight, fn func(ctx context.Context) (*Image, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.image, f.err = nil, fmt.Errorf("coalesced image call panicked: %v", r)
+		}
+
+		g.mu.Lock()
+		if g.calls[key] == f {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+
+		f.cancel()
+		close(f.done)
+	}()
+
+	f.image, f.err = fn(ctx)
+}
+
+// leave removes a waiter whose context ended, the last one to leave cancels the request
+func (g *flightGroup) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f.waiters--
+	if f.waiters == 0 {
+		f.cancel()
+		// later callers start a new request instead of joining the cancelled one
+		if g.calls[key] == f {
+			delete(g.calls, key)
+		}
+	}
+}
+
+// flights returns the client's flight group, creating it on first use
+func (c *Client) flights() *flightGroup {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.flightGroup == nil {
+		c.flightGroup = &flightGroup{}
+	}
+
+	return c.flightGroup
+}
+
+// CoalescedCalls is the number of image calls that shared another call's request instead of sending their own
+func (c *Client) CoalescedCalls() int64 {
+	return atomic.LoadInt64(&c.flights().coalesced)
+}
@@ -0,0 +1,174 @@
+package dagpi_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// slowServer answers every request once its clock is advanced by a second
+func slowServer() (*dagpitest.Server, *dagpitest.Clock) {
+	clock := dagpitest.NewClock(time.Time{})
+	server := dagpitest.NewServer("")
+	server.Clock = clock
+	server.SetLatency(time.Second)
+
+	return server, clock
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	server, clock := slowServer()
+	defer server.Close()
+
+	client := server.Client()
+	client.Coalesce = true
+
+	const callers = 5
+	input := server.URL + "/assets/input.png"
+	images := make([][]byte, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			images[i], errs[i] = client.Pixelate(input)
+		}(i)
+	}
+
+	clock.BlockUntil(1)
+	waitFor(t, "every caller to join the request", func() bool { return client.CoalescedCalls() == callers-1 })
+	clock.Advance(time.Second)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d got %v", i, errs[i])
+		}
+		if !bytes.Equal(images[i], images[0]) {
+			t.Errorf("caller %d got a different image", i)
+		}
+	}
+	if server.Requests("image/pixel") != 1 {
+		t.Errorf("the API got %d requests, want 1", server.Requests("image/pixel"))
+	}
+}
+
+func TestCoalesceLeaderCancelled(t *testing.T) {
+	server, clock := slowServer()
+	defer server.Close()
+
+	client := server.Client()
+	client.Coalesce = true
+	input := server.URL + "/assets/input.png"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := client.ApplyContext(ctx, dagpi.EffectPixelate, input)
+		leader <- err
+	}()
+	clock.BlockUntil(1)
+
+	follower := make(chan error, 1)
+	go func() {
+		_, err := client.ApplyContext(context.Background(), dagpi.EffectPixelate, input)
+		follower <- err
+	}()
+	waitFor(t, "the follower to join the request", func() bool { return client.CoalescedCalls() == 1 })
+
+	cancel()
+	if err := <-leader; err != context.Canceled {
+		t.Fatalf("the cancelled leader got %v, want context.Canceled", err)
+	}
+
+	// the follower is still waiting so the request keeps going
+	clock.Advance(time.Second)
+	if err := <-follower; err != nil {
+		t.Errorf("the follower got %v, want the shared result", err)
+	}
+	if server.Requests("image/pixel") != 1 {
+		t.Errorf("the API got %d requests, want 1", server.Requests("image/pixel"))
+	}
+}
+
+func TestCoalesceLastWaiterCancels(t *testing.T) {
+	server, clock := slowServer()
+	defer server.Close()
+
+	client := server.Client()
+	client.Coalesce = true
+	input := server.URL + "/assets/input.png"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ApplyContext(ctx, dagpi.EffectPixelate, input)
+		done <- err
+	}()
+	clock.BlockUntil(1)
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("ApplyContext() = %v, want context.Canceled", err)
+	}
+	// the server stops its latency timer once the request is cancelled
+	waitFor(t, "the shared request to be cancelled", func() bool { return clock.Timers() == 0 })
+
+	// a new call starts its own request instead of joining the cancelled one
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.ApplyContext(context.Background(), dagpi.EffectPixelate, input)
+		result <- err
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if err := <-result; err != nil {
+		t.Errorf("ApplyContext() after the cancelled flight = %v", err)
+	}
+}
+
+type panicTransport struct{}
+
+func (panicTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	panic("transport exploded")
+}
+
+func TestCoalescePanic(t *testing.T) {
+	client := &dagpi.Client{Coalesce: true, HTTPClient: &http.Client{Transport: panicTransport{}}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Pixelate("https://example.com/a.png")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "transport exploded") {
+			t.Errorf("Pixelate() = %v, want the panic as an error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Pixelate() never returned after the request panicked")
+	}
+}
@@ -0,0 +1,281 @@
+// Package contract checks that a Dagpi compatible API still answers every route the client calls with the
+// shapes the client expects. Point it at the real API, a proxy or a dagpitest.Server and it reports the drift.
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/internal/routes"
+)
+
+// DefaultImageURL is the input image passed to image routes when Options.ImageURL is not set
+const DefaultImageURL = "https://avatars.githubusercontent.com/u/9919?v=4"
+
+// Options for a contract run
+type Options struct {
+	// ImageURL is passed to image routes as url and url2, defaults to DefaultImageURL
+	ImageURL string
+
+	// Routes limits the run to these paths, like "data/joke" or "image/pixel". Empty runs every route,
+	// a path that isn't a route makes Run return an error rather than pass without checking it.
+	Routes []string
+
+	// SkipImages only runs the data routes
+	SkipImages bool
+
+	// RateLimiter, when set, is waited on before every request. The real API allows 60 requests a minute.
+	RateLimiter dagpi.RateLimiter
+
+	// HTTPClient sends the requests, defaults to one with a 30 second timeout
+	HTTPClient *http.Client
+}
+
+// Result is what a single route answered with and what was wrong with it
+type Result struct {
+	Route       string   `json:"route"`
+	Status      int      `json:"status"`
+	ContentType string   `json:"contentType,omitempty"`
+	DurationMs  int64    `json:"durationMs"`
+	OK          bool     `json:"ok"`
+	Problems    []string `json:"problems,omitempty"`
+}
+
+// Report is the outcome of a run, it marshals to json for CI
+type Report struct {
+	BaseURL    string    `json:"baseUrl"`
+	Started    time.Time `json:"started"`
+	DurationMs int64     `json:"durationMs"`
+	Passed     int       `json:"passed"`
+	Failed     int       `json:"failed"`
+	Results    []Result  `json:"results"`
+}
+
+// OK reports whether every route passed
+func (r *Report) OK() bool {
+	return r.Failed == 0
+}
+
+// JSON returns the report as indented json
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+func selected(opts Options) ([]routes.Route, error) {
+	all := routes.All()
+	if len(opts.Routes) == 0 {
+		if opts.SkipImages {
+			return routes.Data, nil
+		}
+		return all, nil
+	}
+
+	var unknown []string
+	for _, path := range opts.Routes {
+		if _, ok := routes.Find(normalize(path)); !ok {
+			unknown = append(unknown, path)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown routes: %s", strings.Join(unknown, ", "))
+	}
+
+	var picked []routes.Route
+	for _, route := range all {
+		if opts.SkipImages && route.IsImage() {
+			continue
+		}
+		for _, path := range opts.Routes {
+			if normalize(path) == route.Path {
+				picked = append(picked, route)
+			}
+		}
+	}
+
+	return picked, nil
+}
+
+// normalize turns " /data/joke/" into "data/joke"
+func normalize(path string) string {
+	return strings.Trim(strings.TrimSpace(path), "/")
+}
+
+// Run calls every route on baseURL with token and checks each response. It only returns an error when
+// Options.Routes names a route that doesn't exist or the run itself can't continue, drift is reported in the Report.
+func Run(ctx context.Context, baseURL string, token string, opts Options) (*Report, error) {
+	picked, err := selected(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ImageURL == "" {
+		opts.ImageURL = DefaultImageURL
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	report := &Report{BaseURL: baseURL, Started: time.Now()}
+	for _, route := range picked {
+		if opts.RateLimiter != nil {
+			err := opts.RateLimiter.Wait(ctx)
+			if err != nil {
+				return report, err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		result := check(ctx, strings.TrimRight(baseURL, "/"), token, route, opts)
+		if result.OK {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.DurationMs = time.Since(report.Started).Milliseconds()
+
+	return report, nil
+}
+
+func check(ctx context.Context, baseURL string, token string, route routes.Route, opts Options) Result {
+	result := Result{Route: route.Path}
+
+	query := route.Query(opts.ImageURL)
+	target := baseURL + "/" + route.Path + "/"
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	started := time.Now()
+	body, resp, err := get(ctx, opts.HTTPClient, target, token)
+	result.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+		return result
+	}
+
+	result.Status = resp.StatusCode
+	result.ContentType = resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK {
+		result.Problems = append(result.Problems, fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body))))
+		return result
+	}
+
+	if route.IsImage() {
+		result.Problems = checkImage(route, body)
+	} else {
+		result.Problems = checkData(route, body)
+	}
+	result.OK = len(result.Problems) == 0
+
+	return result
+}
+
+func get(ctx context.Context, httpClient *http.Client, target string, token string) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Add("Authorization", token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, resp, nil
+}
+
+func checkImage(route routes.Route, body []byte) []string {
+	want := "image/png"
+	if route.Animated {
+		want = "image/gif"
+	}
+
+	got := http.DetectContentType(body)
+	if !strings.HasPrefix(got, "image/") {
+		return []string{fmt.Sprintf("body is %s, not an image", got)}
+	}
+	if got != want {
+		return []string{fmt.Sprintf("body is %s, want %s", got, want)}
+	}
+
+	return nil
+}
+
+func checkData(route routes.Route, body []byte) []string {
+	var data map[string]interface{}
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return []string{fmt.Sprintf("body is not a json object: %v", err)}
+	}
+
+	var problems []string
+	for _, field := range route.Fields {
+		value, ok := lookup(data, field.Name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing field %s", field.Name))
+			continue
+		}
+		if got := jsonType(value); got != field.Type {
+			problems = append(problems, fmt.Sprintf("field %s is %s, want %s", field.Name, got, field.Type))
+		}
+	}
+
+	return problems
+}
+
+// lookup follows a dotted field name into nested objects
+func lookup(data map[string]interface{}, name string) (interface{}, bool) {
+	var value interface{} = data
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case nil:
+		return "null"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,178 @@
+package contract_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/contract"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func run(t *testing.T, baseURL string, token string, opts contract.Options) *contract.Report {
+	t.Helper()
+
+	report, err := contract.Run(context.Background(), baseURL, token, opts)
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	return report
+}
+
+func result(report *contract.Report, route string) contract.Result {
+	for _, result := range report.Results {
+		if result.Route == route {
+			return result
+		}
+	}
+
+	return contract.Result{}
+}
+
+func TestFakeServerPasses(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	report := run(t, server.URL, server.Token, contract.Options{ImageURL: server.URL + "/assets/input.png"})
+	if !report.OK() || report.Passed == 0 {
+		data, _ := report.JSON()
+		t.Errorf("the fake server drifted from the contract:\n%s", data)
+	}
+	if report.Passed != len(report.Results) {
+		t.Errorf("Passed = %d of %d results", report.Passed, len(report.Results))
+	}
+}
+
+func TestDrift(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.Trim(r.URL.Path, "/") {
+		case "data/joke":
+			_, _ = w.Write([]byte(`{"joke": 42}`))
+		case "data/fact":
+			_, _ = w.Write([]byte(`{}`))
+		case "data/roast":
+			_, _ = w.Write([]byte(`not json`))
+		case "image/pixel":
+			_, _ = w.Write([]byte("plain text"))
+		case "image/triggered":
+			_, _ = w.Write(dagpitest.PlaceholderPNG("triggered"))
+		default:
+			http.Error(w, "gone", http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+
+	report := run(t, api.URL, "token", contract.Options{
+		Routes: []string{"data/joke", "data/fact", "data/roast", "image/pixel", "/image/triggered/", "data/wtp"},
+	})
+	if report.OK() || report.Failed != 6 || len(report.Results) != 6 {
+		t.Fatalf("Run() failed %d of %d routes, want all 6", report.Failed, len(report.Results))
+	}
+
+	for route, want := range map[string]string{
+		"data/joke":       "field joke is number, want string",
+		"data/fact":       "missing field fact",
+		"data/roast":      "not a json object",
+		"image/pixel":     "not an image",
+		"image/triggered": "body is image/png, want image/gif",
+		"data/wtp":        "status 404",
+	} {
+		problems := strings.Join(result(report, route).Problems, "; ")
+		if !strings.Contains(problems, want) {
+			t.Errorf("%s problems = %q, want %q", route, problems, want)
+		}
+	}
+}
+
+func TestFaults(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.Fail("data/joke", dagpitest.Fault{Status: http.StatusServiceUnavailable, Message: "down"})
+
+	report := run(t, server.URL, server.Token, contract.Options{SkipImages: true})
+	if report.Failed != 1 {
+		t.Errorf("Failed = %d, want only data/joke", report.Failed)
+	}
+	if joke := result(report, "data/joke"); joke.OK || joke.Status != http.StatusServiceUnavailable {
+		t.Errorf("data/joke = %+v, want a failed 503", joke)
+	}
+	for _, result := range report.Results {
+		if strings.HasPrefix(result.Route, "image/") {
+			t.Errorf("SkipImages still ran %s", result.Route)
+		}
+	}
+
+	badToken := run(t, server.URL, "wrong", contract.Options{Routes: []string{"data/fact"}})
+	if fact := result(badToken, "data/fact"); fact.Status != http.StatusForbidden {
+		t.Errorf("data/fact with a bad token = %d, want 403", fact.Status)
+	}
+
+	var decoded contract.Report
+	data, _ := report.JSON()
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Failed != 1 {
+		t.Errorf("JSON() doesn't round trip: %v", err)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	clock := dagpitest.NewClock(time.Time{})
+	limiter := dagpi.NewLimiter(1, time.Minute)
+	limiter.Clock = clock
+
+	done := make(chan *contract.Report, 1)
+	go func() {
+		report, _ := contract.Run(context.Background(), server.URL, server.Token, contract.Options{
+			Routes:      []string{"data/joke", "data/fact"},
+			RateLimiter: limiter,
+		})
+		done <- report
+	}()
+
+	clock.BlockUntil(1)
+	if server.Requests("") != 1 {
+		t.Errorf("the API got %d requests before the limiter refilled, want 1", server.Requests(""))
+	}
+	clock.Advance(time.Minute)
+
+	if report := <-done; report.Passed != 2 {
+		t.Errorf("Passed = %d, want 2", report.Passed)
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := contract.Run(ctx, server.URL, server.Token, contract.Options{SkipImages: true})
+	if err != context.Canceled || len(report.Results) != 0 {
+		t.Errorf("Run() = %d results, %v, want none and context.Canceled", len(report.Results), err)
+	}
+}
+
+func TestUnknownRoutes(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	_, err := contract.Run(context.Background(), server.URL, server.Token, contract.Options{Routes: []string{"data/joke", "data/jokee"}})
+	if err == nil || !strings.Contains(err.Error(), "data/jokee") {
+		t.Errorf("Run() with a typo = %v, want an error naming data/jokee", err)
+	}
+	if server.Requests("") != 0 {
+		t.Errorf("the API got %d requests, want the run refused before any", server.Requests(""))
+	}
+
+	report := run(t, server.URL, server.Token, contract.Options{Routes: []string{" data/joke", "image/pixel"}, SkipImages: true})
+	if len(report.Results) != 1 || report.Results[0].Route != "data/joke" {
+		t.Errorf("Run() = %+v, want only data/joke with images skipped", report.Results)
+	}
+}
@@ -1,6 +1,7 @@
 package dagpi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,25 +9,107 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
+// DefaultBaseURL is the API the client talks to when BaseURL is not set
+const DefaultBaseURL = "https://api.dagpi.xyz"
+
 // Client Struct
 type Client struct {
 	Auth string
+
+	// BaseURL of the API, defaults to DefaultBaseURL. Point it at a dagpitest.Server in tests.
+	BaseURL string
+
+	// HTTPClient sends requests to the API, defaults to a plain http.Client.
+	// Set its Transport to record, replay or inspect traffic.
+	HTTPClient *http.Client
+
+	// Preflight, when set, validates the image urls passed to image manipulation calls before they reach the API
+	Preflight *Preflight
+
+	// Uploader hosts intermediate images for pipelines
+	Uploader Uploader
+
+	// RateLimiter, when set, is waited on before every request to the API
+	RateLimiter RateLimiter
+
+	// Cache, when set, stores the results of deterministic image manipulation calls
+	Cache Cache
+
+	// CacheTTL is how long cached images are kept, zero keeps them until they are evicted
+	CacheTTL time.Duration
+
+	// Coalesce makes concurrent identical image calls share a single request to the API.
+	// Every caller gets the same buffer so it must not be modified.
+	Coalesce bool
+
+	// Clock is used for everything time dependent in the client, defaults to SystemClock.
+	// Limiters, caches and preflights have their own Clock field.
+	Clock Clock
+
+	// HAR, when set, captures every request to the API and its response for bug reports
+	HAR *HAR
+
+	// Fallback, when set, renders image calls locally when the API can't
+	Fallback *FallbackPolicy
+
+	mu          sync.Mutex
+	flightGroup *flightGroup
+	breaker     breaker
 }
 
-// request to get data
-func httpGet(url string, c *Client) (map[string]interface{}, error) {
-	httpClient := &http.Client{}
+// APIError is returned by every Data and Image call when the API answers with a non 2xx status,
+// instead of the error body being decoded as the result
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("dagpi api responded with %d: %s", e.StatusCode, e.Message)
+}
+
+func (c *Client) clock() Clock {
+	return clockOrSystem(c.Clock)
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+
+	return strings.TrimRight(c.BaseURL, "/")
+}
+
+// waits for the rate limiter, if there is one
+func waitRateLimit(ctx context.Context, c *Client) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+
+	return c.RateLimiter.Wait(ctx)
+}
+
+// sends an authorized GET request and returns the body of a successful response
+func request(ctx context.Context, url string, c *Client) ([]byte, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("Authorization", c.Auth)
+	started := c.clock().Now()
 	resp, err := httpClient.Do(req)
 	if err != nil {
+		c.HAR.record(req, nil, nil, started, c.clock().Now().Sub(started), err)
 		return nil, err
 	}
 
@@ -37,45 +120,120 @@ func httpGet(url string, c *Client) (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	var data map[string]interface{}
-	decoder := json.NewDecoder(resp.Body)
-
-	err = decoder.Decode(&data)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.HAR.record(req, resp, body, started, c.clock().Now().Sub(started), err)
 	if err != nil {
 		return nil, err
 	}
 
-	return data, nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	return body, nil
 }
 
-// Attempting to get an image's buffer
-func getImageBuffer(url string, c *Client) ([]byte, error) {
-	httpClient := &http.Client{}
+// errors from the API usually come as {"message": "..."}, fall back to the raw body if not
+func newAPIError(statusCode int, body []byte) *APIError {
+	var data struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &data); err == nil && data.Message != "" {
+		return &APIError{StatusCode: statusCode, Message: data.Message}
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	return &APIError{StatusCode: statusCode, Message: strings.TrimSpace(string(body))}
+}
+
+// request to get data
+func httpGet(url string, c *Client) (map[string]interface{}, error) {
+	ctx := context.Background()
+	err := waitRateLimit(ctx, c)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", c.Auth)
-	resp, err := httpClient.Do(req)
+	body, err := request(ctx, url, c)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func(Body io.ReadCloser) {
-		err = Body.Close()
-	}(resp.Body)
+	var data map[string]interface{}
+	err = json.Unmarshal(body, &data)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	return data, nil
+}
+
+// Attempting to get an image's buffer
+func getImageBuffer(url string, c *Client) ([]byte, error) {
+	return getImageBufferContext(context.Background(), url, c)
+}
+
+func getImageBufferContext(ctx context.Context, url string, c *Client) ([]byte, error) {
+	image, err := getImage(ctx, url, c)
 	if err != nil {
 		return nil, err
 	}
 
-	return body, nil
+	return image.Data, nil
+}
+
+// getImage serves an image call from the cache, the API or the fallback renderer
+func getImage(ctx context.Context, url string, c *Client) (*Image, error) {
+	if c.Cache != nil {
+		if key := cacheKey(url); key != "" {
+			if buffer, ok := c.Cache.Get(key); ok {
+				return &Image{Data: buffer, Backend: BackendCache}, nil
+			}
+		}
+	}
+
+	fetch := func(ctx context.Context) (*Image, error) {
+		if c.Preflight != nil {
+			err := c.Preflight.checkRequest(ctx, url)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.Fallback != nil {
+			return c.Fallback.fetch(ctx, url, c)
+		}
+
+		return fetchRemote(ctx, url, c, false)
+	}
+
+	if c.Coalesce {
+		return c.flights().do(ctx, url, fetch)
+	}
+
+	return fetch(ctx)
+}
+
+// fetchRemote gets an image from the API and caches it. acquired skips the rate limiter when a token was already taken.
+func fetchRemote(ctx context.Context, url string, c *Client, acquired bool) (*Image, error) {
+	if !acquired {
+		err := waitRateLimit(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := request(ctx, url, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Cache != nil {
+		if key := cacheKey(url); key != "" {
+			c.Cache.Set(key, body, c.CacheTTL)
+		}
+	}
+
+	return &Image{Data: body, Backend: BackendRemote}, nil
 }
 
 // As new routes are created in the API, their method calls will be added to the bottom of their respective region
@@ -85,7 +243,7 @@ func getImageBuffer(url string, c *Client) ([]byte, error) {
 // WTP returns an interface with all the Pokemon data
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/whos-that-pokemon/who's-that-pokemon?
 func (c *Client) WTP() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/wtp", c)
+	data, err := httpGet(c.baseURL()+"/data/wtp", c)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +254,7 @@ func (c *Client) WTP() (interface{}, error) {
 // Roast returns an interface containing a roast
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/roast/roast
 func (c *Client) Roast() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/roast", c)
+	data, err := httpGet(c.baseURL()+"/data/roast", c)
 	roast := data["roast"]
 	if err != nil {
 		return nil, err
@@ -108,7 +266,7 @@ func (c *Client) Roast() (interface{}, error) {
 // Joke returns an interface containing a joke & id
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/joke/joke
 func (c *Client) Joke() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/joke", c)
+	data, err := httpGet(c.baseURL()+"/data/joke", c)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +277,7 @@ func (c *Client) Joke() (interface{}, error) {
 // Fact returns an interface containing a fact
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/fact/fact
 func (c *Client) Fact() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/fact", c)
+	data, err := httpGet(c.baseURL()+"/data/fact", c)
 	fact := data["fact"]
 	if err != nil {
 		return nil, err
@@ -131,7 +289,7 @@ func (c *Client) Fact() (interface{}, error) {
 // Eightball returns an interface containing a response to 8ball question
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/8ball/8ball
 func (c *Client) Eightball() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/8ball", c)
+	data, err := httpGet(c.baseURL()+"/data/8ball", c)
 	response := data["response"]
 	if err != nil {
 		return nil, err
@@ -143,7 +301,7 @@ func (c *Client) Eightball() (interface{}, error) {
 // Yomama returns an interface containing a description of yomama
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/yomama/yomama
 func (c *Client) Yomama() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/yomama", c)
+	data, err := httpGet(c.baseURL()+"/data/yomama", c)
 	description := data["description"]
 	if err != nil {
 		return nil, err
@@ -155,7 +313,7 @@ func (c *Client) Yomama() (interface{}, error) {
 // RandomWaifu returns an interface containing data of a random waifu
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/random-waifu/random-waifu
 func (c *Client) RandomWaifu() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/waifu", c)
+	data, err := httpGet(c.baseURL()+"/data/waifu", c)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +324,7 @@ func (c *Client) RandomWaifu() (interface{}, error) {
 // Waifu returns an interface containing data of a given waifu
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/waifu-saerch/waifu-saerch
 func (c *Client) Waifu(waifuName string) (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/"+waifuName, c)
+	data, err := httpGet(c.baseURL()+"/data/"+waifuName, c)
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +335,7 @@ func (c *Client) Waifu(waifuName string) (interface{}, error) {
 // PickupLine returns an interface containing category & joke
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/pickup-line/pickup-line
 func (c *Client) PickupLine() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/pickupline", c)
+	data, err := httpGet(c.baseURL()+"/data/pickupline", c)
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +346,7 @@ func (c *Client) PickupLine() (interface{}, error) {
 // HeadLine returns an interface containing text and a bool, 'fake'
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/headline/headline
 func (c *Client) HeadLine() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/headline", c)
+	data, err := httpGet(c.baseURL()+"/data/headline", c)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +357,7 @@ func (c *Client) HeadLine() (interface{}, error) {
 // GTL returns an interface containing data of a random logo (Guess the Logo)
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/guess-the-logo/guess-the-logo
 func (c *Client) GTL() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/logo", c)
+	data, err := httpGet(c.baseURL()+"/data/logo", c)
 	if err != nil {
 		return nil, err
 	}
@@ -210,7 +368,7 @@ func (c *Client) GTL() (interface{}, error) {
 // Flag returns an interface containing data of a random flag
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/flag/flag
 func (c *Client) Flag() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/flag", c)
+	data, err := httpGet(c.baseURL()+"/data/flag", c)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +379,7 @@ func (c *Client) Flag() (interface{}, error) {
 // Captcha get a random captcha and answer
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/captcha/captcha
 func (c *Client) Captcha() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/captcha", c)
+	data, err := httpGet(c.baseURL()+"/data/captcha", c)
 	if err != nil {
 		return nil, err
 	}
@@ -232,7 +390,7 @@ func (c *Client) Captcha() (interface{}, error) {
 // Typeracer get a sentence on an image, with a sentence to create typeracer games
 // Docs: https://dagpi.docs.apiary.io/#reference/data-api/typeracer/typeracer
 func (c *Client) Typeracer() (interface{}, error) {
-	data, err := httpGet("https://api.dagpi.xyz/data/typeracer", c)
+	data, err := httpGet(c.baseURL()+"/data/typeracer", c)
 	if err != nil {
 		return nil, err
 	}
@@ -247,7 +405,7 @@ func (c *Client) Typeracer() (interface{}, error) {
 // Pixelate Allows you to pixelate an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/pixel/pixel
 func (c *Client) Pixelate(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/pixel/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/pixel/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +416,7 @@ func (c *Client) Pixelate(url string) ([]byte, error) {
 // Mirror an image along the y-axis
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/mirror/mirror
 func (c *Client) Mirror(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/mirror/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/mirror/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -269,7 +427,7 @@ func (c *Client) Mirror(url string) ([]byte, error) {
 // FlipImage flip an image
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/flip/flip
 func (c *Client) FlipImage(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/flip/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/flip/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -280,7 +438,7 @@ func (c *Client) FlipImage(url string) ([]byte, error) {
 // Colors Allows you to get an Image with the colors present in the image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/colors/colors
 func (c *Client) Colors(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/colors/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/colors/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -291,7 +449,7 @@ func (c *Client) Colors(url string) ([]byte, error) {
 // America Let the star-spangled banner of the free and the brave soar.
 // Docs:  https://dagpi.docs.apiary.io/#reference/images-api/america/america
 func (c *Client) America(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/america/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/america/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -302,7 +460,7 @@ func (c *Client) America(url string) ([]byte, error) {
 // Communism Support the soviet union comrade. Let the red flag fly!
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/communism/communism
 func (c *Client) Communism(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/communism/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/communism/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -313,7 +471,7 @@ func (c *Client) Communism(url string) ([]byte, error) {
 // Triggered Allows you to get a triggered gif.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/triggered/triggered
 func (c *Client) Triggered(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/triggered/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/triggered/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -324,7 +482,7 @@ func (c *Client) Triggered(url string) ([]byte, error) {
 // ExpandImage animation that streches an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/expand/expand
 func (c *Client) ExpandImage(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/expand/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/expand/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -335,7 +493,7 @@ func (c *Client) ExpandImage(url string) ([]byte, error) {
 // Wasted Allows you to get an image with GTA V Wasted screen.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/wasted/wasted
 func (c *Client) Wasted(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/wasted/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/wasted/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -346,7 +504,7 @@ func (c *Client) Wasted(url string) ([]byte, error) {
 // Sketch Cool efffect that shows how an image would have been created by an artist.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/sketch/sketch
 func (c *Client) Sketch(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/sketch/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/sketch/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -357,7 +515,7 @@ func (c *Client) Sketch(url string) ([]byte, error) {
 // SpinImage You spin me right round baby.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/spin/spin
 func (c *Client) SpinImage(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/spin/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/spin/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -368,7 +526,7 @@ func (c *Client) SpinImage(url string) ([]byte, error) {
 // PetPet Pet pet gif
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/petpet/petpet
 func (c *Client) PetPet(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/petpet/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/petpet/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -379,7 +537,7 @@ func (c *Client) PetPet(url string) ([]byte, error) {
 // Bonk Get bonked on my cheems
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/bonk/bonk
 func (c *Client) Bonk(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/bonk/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/bonk/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -390,7 +548,7 @@ func (c *Client) Bonk(url string) ([]byte, error) {
 // Bomb Explosion
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/bomb/bomb
 func (c *Client) Bomb(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/bomb/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/bomb/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -401,7 +559,7 @@ func (c *Client) Bomb(url string) ([]byte, error) {
 // Shake a gif by having it wiggle.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/shake/shake
 func (c *Client) Shake(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/shake/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/shake/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -412,7 +570,7 @@ func (c *Client) Shake(url string) ([]byte, error) {
 // Invert Allows you to get an image with an inverted color effect.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/invert/invert
 func (c *Client) Invert(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/invert/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/invert/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +581,7 @@ func (c *Client) Invert(url string) ([]byte, error) {
 // Sobel Allows you to get an image with the sobel effect.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/sobel/sobel
 func (c *Client) Sobel(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/sobel/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/sobel/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -434,7 +592,7 @@ func (c *Client) Sobel(url string) ([]byte, error) {
 // Hog Histogram of Oriented Gradients for an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/hog/hog
 func (c *Client) Hog(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/hog/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/hog/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -445,7 +603,7 @@ func (c *Client) Hog(url string) ([]byte, error) {
 // Triangle Cool triangle effect for an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/triangle/triangle
 func (c *Client) Triangle(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/triangle/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/triangle/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -456,7 +614,7 @@ func (c *Client) Triangle(url string) ([]byte, error) {
 // Blur Blurs a given image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/blur/blur
 func (c *Client) Blur(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/blur/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/blur/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -467,7 +625,7 @@ func (c *Client) Blur(url string) ([]byte, error) {
 // RGB Get an RGB graph of an image's colors.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/rgb/rgb
 func (c *Client) RGB(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/rgb/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/rgb/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -478,7 +636,7 @@ func (c *Client) RGB(url string) ([]byte, error) {
 // Angel Image on the Angels face.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/angel/angel
 func (c *Client) Angel(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/angel/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/angel/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -489,7 +647,7 @@ func (c *Client) Angel(url string) ([]byte, error) {
 // Satan Put an image on the devil.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/satan/satan
 func (c *Client) Satan(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/satan/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/satan/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -500,7 +658,7 @@ func (c *Client) Satan(url string) ([]byte, error) {
 // Delete Generates a Windows error meme based on a given image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/delete/delete
 func (c *Client) Delete(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/delete/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/delete/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -511,7 +669,7 @@ func (c *Client) Delete(url string) ([]byte, error) {
 // Fedora Tips fedora in appreciation. Perry the Platypus.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/fedora/fedora
 func (c *Client) Fedora(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/fedora/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/fedora/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -522,7 +680,7 @@ func (c *Client) Fedora(url string) ([]byte, error) {
 // Hitler ?????
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/hitler/hitler
 func (c *Client) Hitler(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/hitler/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/hitler/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -533,7 +691,7 @@ func (c *Client) Hitler(url string) ([]byte, error) {
 // Lego Every group of pixels is a lego brick
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/lego/lego
 func (c *Client) Lego(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/lego/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/lego/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -544,7 +702,7 @@ func (c *Client) Lego(url string) ([]byte, error) {
 // Wanted poster of an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/wanted/wanted
 func (c *Client) Wanted(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/wanted/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/wanted/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -555,7 +713,7 @@ func (c *Client) Wanted(url string) ([]byte, error) {
 // Stringify Turn your image into a ball of yarn.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/stringify/stringify
 func (c *Client) Stringify(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/stringify/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/stringify/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +724,7 @@ func (c *Client) Stringify(url string) ([]byte, error) {
 // Burn Light your image on fire
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/burn/burn
 func (c *Client) Burn(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/burn/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/burn/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -577,7 +735,7 @@ func (c *Client) Burn(url string) ([]byte, error) {
 // Earth The green and blue of the earth
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/earth/earth
 func (c *Client) Earth(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/earth/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/earth/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -588,7 +746,7 @@ func (c *Client) Earth(url string) ([]byte, error) {
 // Freeze Blue ice like tint.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/freeze/freeze
 func (c *Client) Freeze(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/freeze/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/freeze/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -599,7 +757,7 @@ func (c *Client) Freeze(url string) ([]byte, error) {
 // Ground The poower of the earth
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/earth/earth
 func (c *Client) Ground(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/ground/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/ground/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -610,7 +768,7 @@ func (c *Client) Ground(url string) ([]byte, error) {
 // Mosiac Turn an image into a roman mosiac.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/mosiac/mosiac
 func (c *Client) Mosiac(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/mosiac/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/mosiac/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -621,7 +779,7 @@ func (c *Client) Mosiac(url string) ([]byte, error) {
 // Sithlord Put an image on the Laughs in Sithlord meme.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/sithlord/sithlord
 func (c *Client) Sithlord(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/sith/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/sith/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -632,7 +790,7 @@ func (c *Client) Sithlord(url string) ([]byte, error) {
 // Jail Put an image behind bars.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/jail/jail
 func (c *Client) Jail(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/jail/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/jail/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -643,7 +801,7 @@ func (c *Client) Jail(url string) ([]byte, error) {
 // Shatter Put an image behind bars.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/shatter/shatter
 func (c *Client) Shatter(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/shatter/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/shatter/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -670,7 +828,7 @@ func (c *Client) Pride(url string, flag string) ([]byte, error) {
 	}
 	for _, acceptableFlag := range acceptableFlags {
 		if acceptableFlag == strings.ToLower(flag) {
-			imgBuffer, err := getImageBuffer("https://api.dagpi.xyz/image/pride/?url="+url+"&flag="+flag, c)
+			imgBuffer, err := getImageBuffer(c.baseURL()+"/image/pride/?url="+url+"&flag="+flag, c)
 			if err != nil {
 				return nil, err
 			}
@@ -686,7 +844,7 @@ func (c *Client) Pride(url string, flag string) ([]byte, error) {
 // Trash Image is trash.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/trash/trash
 func (c *Client) Trash(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/trash/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/trash/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -697,7 +855,7 @@ func (c *Client) Trash(url string) ([]byte, error) {
 // Deepfry an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/deepfry/deepfry
 func (c *Client) Deepfry(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/deepfry/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/deepfry/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -708,7 +866,7 @@ func (c *Client) Deepfry(url string) ([]byte, error) {
 // Ascii Cool hackerman effect for an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/ascii/ascii
 func (c *Client) Ascii(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/ascii/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/ascii/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -719,7 +877,7 @@ func (c *Client) Ascii(url string) ([]byte, error) {
 // Charcoal Image into a charcoal drawing.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/charcoal/charcoal
 func (c *Client) Charcoal(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/charcoal/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/charcoal/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -730,7 +888,7 @@ func (c *Client) Charcoal(url string) ([]byte, error) {
 // Posterize Posterizes an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/posterize/posterize
 func (c *Client) Posterize(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/poster/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/poster/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -741,7 +899,7 @@ func (c *Client) Posterize(url string) ([]byte, error) {
 // Sepia Tone an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/sepia/sepia
 func (c *Client) Sepia(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/sepia/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/sepia/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -752,7 +910,7 @@ func (c *Client) Sepia(url string) ([]byte, error) {
 // Swirl an image.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/swirl/swirl
 func (c *Client) Swirl(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/swirl/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/swirl/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -763,7 +921,7 @@ func (c *Client) Swirl(url string) ([]byte, error) {
 // Paint Turn an image into art.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/paint/paint
 func (c *Client) Paint(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/paint/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/paint/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -774,7 +932,7 @@ func (c *Client) Paint(url string) ([]byte, error) {
 // Night Turn a day into night.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/night/night
 func (c *Client) Night(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/night/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/night/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -785,7 +943,7 @@ func (c *Client) Night(url string) ([]byte, error) {
 // Rainbow Some trippy light effects.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/rainbow/rainbow
 func (c *Client) Rainbow(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/rainbow/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/rainbow/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -796,7 +954,7 @@ func (c *Client) Rainbow(url string) ([]byte, error) {
 // Magik The much loved magik endpoint.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/magik/magik
 func (c *Client) Magik(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/magik/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/magik/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -807,7 +965,7 @@ func (c *Client) Magik(url string) ([]byte, error) {
 // FivegOneg The meme.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/five-guys-one-girl/five-guys-one-girl
 func (c *Client) FivegOneg(url1 string, url2 string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/5g1g/?url="+url1+"&url2="+url2, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/5g1g/?url="+url1+"&url2="+url2, c)
 	if err != nil {
 		return nil, err
 	}
@@ -818,7 +976,7 @@ func (c *Client) FivegOneg(url1 string, url2 string) ([]byte, error) {
 // WhyAreYouGay The meme.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/why-are-you-gay/why-are-you-gay
 func (c *Client) WhyAreYouGay(url1 string, url2 string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/whyareyougay/?url="+url1+"&url2="+url2, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/whyareyougay/?url="+url1+"&url2="+url2, c)
 	if err != nil {
 		return nil, err
 	}
@@ -829,7 +987,7 @@ func (c *Client) WhyAreYouGay(url1 string, url2 string) ([]byte, error) {
 // Slap Have one image slap another.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/slap/slap
 func (c *Client) Slap(url1 string, url2 string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/slap/?url="+url1+"&url2="+url2, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/slap/?url="+url1+"&url2="+url2, c)
 	if err != nil {
 		return nil, err
 	}
@@ -840,7 +998,7 @@ func (c *Client) Slap(url1 string, url2 string) ([]byte, error) {
 // Obama The meme.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/obama/obama
 func (c *Client) Obama(url1 string, url2 string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/obama/?url="+url1+"&url2="+url2, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/obama/?url="+url1+"&url2="+url2, c)
 	if err != nil {
 		return nil, err
 	}
@@ -851,7 +1009,7 @@ func (c *Client) Obama(url1 string, url2 string) ([]byte, error) {
 // Tweet The meme.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/tweet/tweet
 func (c *Client) Tweet(url string, username string, text string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/tweet/?url="+url+"&username="+username+"&text="+text, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/tweet/?url="+url+"&username="+username+"&text="+text, c)
 	if err != nil {
 		return nil, err
 	}
@@ -863,14 +1021,14 @@ func (c *Client) Tweet(url string, username string, text string) ([]byte, error)
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/youtube-comment/youtube-comment
 func (c *Client) YouTubeComment(url string, username string, text string, darkMode bool) ([]byte, error) {
 	if darkMode == true {
-		buffer, err := getImageBuffer("https://api.dagpi.xyz/image/yt/?url="+url+"&username="+username+"&text="+text+"&dark="+"true", c)
+		buffer, err := getImageBuffer(c.baseURL()+"/image/yt/?url="+url+"&username="+username+"&text="+text+"&dark="+"true", c)
 		if err != nil {
 			return nil, err
 		}
 
 		return buffer, nil
 	} else {
-		buffer, err := getImageBuffer("https://api.dagpi.xyz/image/yt/?url="+url+"&username="+username+"&text="+text+"&dark="+"false", c)
+		buffer, err := getImageBuffer(c.baseURL()+"/image/yt/?url="+url+"&username="+username+"&text="+text+"&dark="+"false", c)
 		if err != nil {
 			return nil, err
 		}
@@ -883,14 +1041,14 @@ func (c *Client) YouTubeComment(url string, username string, text string, darkMo
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/discord/discord
 func (c *Client) Discord(url string, username string, text string, darkMode bool) ([]byte, error) {
 	if darkMode == true {
-		buffer, err := getImageBuffer("https://api.dagpi.xyz/image/discord/?url="+url+"&username="+username+"&text="+text+"&dark="+"true", c)
+		buffer, err := getImageBuffer(c.baseURL()+"/image/discord/?url="+url+"&username="+username+"&text="+text+"&dark="+"true", c)
 		if err != nil {
 			return nil, err
 		}
 
 		return buffer, nil
 	} else {
-		buffer, err := getImageBuffer("https://api.dagpi.xyz/image/discord/?url="+url+"&username="+username+"&text="+text+"&dark="+"false", c)
+		buffer, err := getImageBuffer(c.baseURL()+"/image/discord/?url="+url+"&username="+username+"&text="+text+"&dark="+"false", c)
 		if err != nil {
 			return nil, err
 		}
@@ -902,7 +1060,7 @@ func (c *Client) Discord(url string, username string, text string, darkMode bool
 // Retromeme The good old memes. Generated.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/retromeme/retromeme
 func (c *Client) Retromeme(url string, topText string, bottomText string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/retromeme/?url="+url+"&top_text="+topText+"&bottom_text="+bottomText, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/retromeme/?url="+url+"&top_text="+topText+"&bottom_text="+bottomText, c)
 	if err != nil {
 		return nil, err
 	}
@@ -913,7 +1071,7 @@ func (c *Client) Retromeme(url string, topText string, bottomText string) ([]byt
 // Motivational The black background with top and bottom motivational text.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/motivational/motivational
 func (c *Client) Motivational(url string, topText string, bottomText string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/motiv/?url="+url+"&top_text="+topText+"&bottom_text="+bottomText, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/motiv/?url="+url+"&top_text="+topText+"&bottom_text="+bottomText, c)
 	if err != nil {
 		return nil, err
 	}
@@ -924,7 +1082,7 @@ func (c *Client) Motivational(url string, topText string, bottomText string) ([]
 // Modernmeme A modern meme generation system that allows reddit ready memes with just one endpoint.
 // Docs: https://dagpi.docs.apiary.io/#reference/images-api/modernmeme/modernmeme
 func (c *Client) Modernmeme(url string, text string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/modernmeme/?url="+url+"&text="+text, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/modernmeme/?url="+url+"&text="+text, c)
 	if err != nil {
 		return nil, err
 	}
@@ -935,7 +1093,7 @@ func (c *Client) Modernmeme(url string, text string) ([]byte, error) {
 // Elmo Burning Elmo Meme
 // Docs: todo add docs when available
 func (c *Client) Elmo(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/elmo/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/elmo/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -946,7 +1104,7 @@ func (c *Client) Elmo(url string) ([]byte, error) {
 // TvStatic Its TV static
 // Docs: todo add docs when available
 func (c *Client) TvStatic(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/tv/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/tv/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -957,7 +1115,7 @@ func (c *Client) TvStatic(url string) ([]byte, error) {
 // Rain Its TV static
 // Docs: todo add docs when available
 func (c *Client) Rain(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/rain/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/rain/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -968,7 +1126,7 @@ func (c *Client) Rain(url string) ([]byte, error) {
 // Glitch todo add description when available
 // Docs: todo add docs when available
 func (c *Client) Glitch(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/glitch/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/glitch/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -979,7 +1137,7 @@ func (c *Client) Glitch(url string) ([]byte, error) {
 // GlitchStatic todo add description when available
 // Docs: todo add docs when available
 func (c *Client) GlitchStatic(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/glitchstatic/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/glitchstatic/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -990,7 +1148,7 @@ func (c *Client) GlitchStatic(url string) ([]byte, error) {
 // Album Make an Album cover!
 // Docs: todo add docs when available
 func (c *Client) Album(url string) ([]byte, error) {
-	buffer, err := getImageBuffer("https://api.dagpi.xyz/image/album/?url="+url, c)
+	buffer, err := getImageBuffer(c.baseURL()+"/image/album/?url="+url, c)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,404 @@
+package dagpimock
+
+//region Data API calls
+
+// WTP records the call and returns the result set with ReturnData
+func (m *Mock) WTP() (interface{}, error) {
+	return m.callData("WTP")
+}
+
+// Roast records the call and returns the result set with ReturnData
+func (m *Mock) Roast() (interface{}, error) {
+	return m.callData("Roast")
+}
+
+// Joke records the call and returns the result set with ReturnData
+func (m *Mock) Joke() (interface{}, error) {
+	return m.callData("Joke")
+}
+
+// Fact records the call and returns the result set with ReturnData
+func (m *Mock) Fact() (interface{}, error) {
+	return m.callData("Fact")
+}
+
+// Eightball records the call and returns the result set with ReturnData
+func (m *Mock) Eightball() (interface{}, error) {
+	return m.callData("Eightball")
+}
+
+// Yomama records the call and returns the result set with ReturnData
+func (m *Mock) Yomama() (interface{}, error) {
+	return m.callData("Yomama")
+}
+
+// RandomWaifu records the call and returns the result set with ReturnData
+func (m *Mock) RandomWaifu() (interface{}, error) {
+	return m.callData("RandomWaifu")
+}
+
+// Waifu records the call and returns the result set with ReturnData
+func (m *Mock) Waifu(waifuName string) (interface{}, error) {
+	return m.callData("Waifu", waifuName)
+}
+
+// PickupLine records the call and returns the result set with ReturnData
+func (m *Mock) PickupLine() (interface{}, error) {
+	return m.callData("PickupLine")
+}
+
+// HeadLine records the call and returns the result set with ReturnData
+func (m *Mock) HeadLine() (interface{}, error) {
+	return m.callData("HeadLine")
+}
+
+// GTL records the call and returns the result set with ReturnData
+func (m *Mock) GTL() (interface{}, error) {
+	return m.callData("GTL")
+}
+
+// Flag records the call and returns the result set with ReturnData
+func (m *Mock) Flag() (interface{}, error) {
+	return m.callData("Flag")
+}
+
+// Captcha records the call and returns the result set with ReturnData
+func (m *Mock) Captcha() (interface{}, error) {
+	return m.callData("Captcha")
+}
+
+// Typeracer records the call and returns the result set with ReturnData
+func (m *Mock) Typeracer() (interface{}, error) {
+	return m.callData("Typeracer")
+}
+
+//endregion
+
+//region Image API calls
+
+// Pixelate records the call and returns the result set with ReturnImage
+func (m *Mock) Pixelate(url string) ([]byte, error) {
+	return m.callImage("Pixelate", url)
+}
+
+// Mirror records the call and returns the result set with ReturnImage
+func (m *Mock) Mirror(url string) ([]byte, error) {
+	return m.callImage("Mirror", url)
+}
+
+// FlipImage records the call and returns the result set with ReturnImage
+func (m *Mock) FlipImage(url string) ([]byte, error) {
+	return m.callImage("FlipImage", url)
+}
+
+// Colors records the call and returns the result set with ReturnImage
+func (m *Mock) Colors(url string) ([]byte, error) {
+	return m.callImage("Colors", url)
+}
+
+// America records the call and returns the result set with ReturnImage
+func (m *Mock) America(url string) ([]byte, error) {
+	return m.callImage("America", url)
+}
+
+// Communism records the call and returns the result set with ReturnImage
+func (m *Mock) Communism(url string) ([]byte, error) {
+	return m.callImage("Communism", url)
+}
+
+// Triggered records the call and returns the result set with ReturnImage
+func (m *Mock) Triggered(url string) ([]byte, error) {
+	return m.callImage("Triggered", url)
+}
+
+// ExpandImage records the call and returns the result set with ReturnImage
+func (m *Mock) ExpandImage(url string) ([]byte, error) {
+	return m.callImage("ExpandImage", url)
+}
+
+// Wasted records the call and returns the result set with ReturnImage
+func (m *Mock) Wasted(url string) ([]byte, error) {
+	return m.callImage("Wasted", url)
+}
+
+// Sketch records the call and returns the result set with ReturnImage
+func (m *Mock) Sketch(url string) ([]byte, error) {
+	return m.callImage("Sketch", url)
+}
+
+// SpinImage records the call and returns the result set with ReturnImage
+func (m *Mock) SpinImage(url string) ([]byte, error) {
+	return m.callImage("SpinImage", url)
+}
+
+// PetPet records the call and returns the result set with ReturnImage
+func (m *Mock) PetPet(url string) ([]byte, error) {
+	return m.callImage("PetPet", url)
+}
+
+// Bonk records the call and returns the result set with ReturnImage
+func (m *Mock) Bonk(url string) ([]byte, error) {
+	return m.callImage("Bonk", url)
+}
+
+// Bomb records the call and returns the result set with ReturnImage
+func (m *Mock) Bomb(url string) ([]byte, error) {
+	return m.callImage("Bomb", url)
+}
+
+// Shake records the call and returns the result set with ReturnImage
+func (m *Mock) Shake(url string) ([]byte, error) {
+	return m.callImage("Shake", url)
+}
+
+// Invert records the call and returns the result set with ReturnImage
+func (m *Mock) Invert(url string) ([]byte, error) {
+	return m.callImage("Invert", url)
+}
+
+// Sobel records the call and returns the result set with ReturnImage
+func (m *Mock) Sobel(url string) ([]byte, error) {
+	return m.callImage("Sobel", url)
+}
+
+// Hog records the call and returns the result set with ReturnImage
+func (m *Mock) Hog(url string) ([]byte, error) {
+	return m.callImage("Hog", url)
+}
+
+// Triangle records the call and returns the result set with ReturnImage
+func (m *Mock) Triangle(url string) ([]byte, error) {
+	return m.callImage("Triangle", url)
+}
+
+// Blur records the call and returns the result set with ReturnImage
+func (m *Mock) Blur(url string) ([]byte, error) {
+	return m.callImage("Blur", url)
+}
+
+// RGB records the call and returns the result set with ReturnImage
+func (m *Mock) RGB(url string) ([]byte, error) {
+	return m.callImage("RGB", url)
+}
+
+// Angel records the call and returns the result set with ReturnImage
+func (m *Mock) Angel(url string) ([]byte, error) {
+	return m.callImage("Angel", url)
+}
+
+// Satan records the call and returns the result set with ReturnImage
+func (m *Mock) Satan(url string) ([]byte, error) {
+	return m.callImage("Satan", url)
+}
+
+// Delete records the call and returns the result set with ReturnImage
+func (m *Mock) Delete(url string) ([]byte, error) {
+	return m.callImage("Delete", url)
+}
+
+// Fedora records the call and returns the result set with ReturnImage
+func (m *Mock) Fedora(url string) ([]byte, error) {
+	return m.callImage("Fedora", url)
+}
+
+// Hitler records the call and returns the result set with ReturnImage
+func (m *Mock) Hitler(url string) ([]byte, error) {
+	return m.callImage("Hitler", url)
+}
+
+// Lego records the call and returns the result set with ReturnImage
+func (m *Mock) Lego(url string) ([]byte, error) {
+	return m.callImage("Lego", url)
+}
+
+// Wanted records the call and returns the result set with ReturnImage
+func (m *Mock) Wanted(url string) ([]byte, error) {
+	return m.callImage("Wanted", url)
+}
+
+// Stringify records the call and returns the result set with ReturnImage
+func (m *Mock) Stringify(url string) ([]byte, error) {
+	return m.callImage("Stringify", url)
+}
+
+// Burn records the call and returns the result set with ReturnImage
+func (m *Mock) Burn(url string) ([]byte, error) {
+	return m.callImage("Burn", url)
+}
+
+// Earth records the call and returns the result set with ReturnImage
+func (m *Mock) Earth(url string) ([]byte, error) {
+	return m.callImage("Earth", url)
+}
+
+// Freeze records the call and returns the result set with ReturnImage
+func (m *Mock) Freeze(url string) ([]byte, error) {
+	return m.callImage("Freeze", url)
+}
+
+// Ground records the call and returns the result set with ReturnImage
+func (m *Mock) Ground(url string) ([]byte, error) {
+	return m.callImage("Ground", url)
+}
+
+// Mosiac records the call and returns the result set with ReturnImage
+func (m *Mock) Mosiac(url string) ([]byte, error) {
+	return m.callImage("Mosiac", url)
+}
+
+// Sithlord records the call and returns the result set with ReturnImage
+func (m *Mock) Sithlord(url string) ([]byte, error) {
+	return m.callImage("Sithlord", url)
+}
+
+// Jail records the call and returns the result set with ReturnImage
+func (m *Mock) Jail(url string) ([]byte, error) {
+	return m.callImage("Jail", url)
+}
+
+// Shatter records the call and returns the result set with ReturnImage
+func (m *Mock) Shatter(url string) ([]byte, error) {
+	return m.callImage("Shatter", url)
+}
+
+// Pride records the call and returns the result set with ReturnImage
+func (m *Mock) Pride(url string, flag string) ([]byte, error) {
+	return m.callImage("Pride", url, flag)
+}
+
+// Trash records the call and returns the result set with ReturnImage
+func (m *Mock) Trash(url string) ([]byte, error) {
+	return m.callImage("Trash", url)
+}
+
+// Deepfry records the call and returns the result set with ReturnImage
+func (m *Mock) Deepfry(url string) ([]byte, error) {
+	return m.callImage("Deepfry", url)
+}
+
+// Ascii records the call and returns the result set with ReturnImage
+func (m *Mock) Ascii(url string) ([]byte, error) {
+	return m.callImage("Ascii", url)
+}
+
+// Charcoal records the call and returns the result set with ReturnImage
+func (m *Mock) Charcoal(url string) ([]byte, error) {
+	return m.callImage("Charcoal", url)
+}
+
+// Posterize records the call and returns the result set with ReturnImage
+func (m *Mock) Posterize(url string) ([]byte, error) {
+	return m.callImage("Posterize", url)
+}
+
+// Sepia records the call and returns the result set with ReturnImage
+func (m *Mock) Sepia(url string) ([]byte, error) {
+	return m.callImage("Sepia", url)
+}
+
+// Swirl records the call and returns the result set with ReturnImage
+func (m *Mock) Swirl(url string) ([]byte, error) {
+	return m.callImage("Swirl", url)
+}
+
+// Paint records the call and returns the result set with ReturnImage
+func (m *Mock) Paint(url string) ([]byte, error) {
+	return m.callImage("Paint", url)
+}
+
+// Night records the call and returns the result set with ReturnImage
+func (m *Mock) Night(url string) ([]byte, error) {
+	return m.callImage("Night", url)
+}
+
+// Rainbow records the call and returns the result set with ReturnImage
+func (m *Mock) Rainbow(url string) ([]byte, error) {
+	return m.callImage("Rainbow", url)
+}
+
+// Magik records the call and returns the result set with ReturnImage
+func (m *Mock) Magik(url string) ([]byte, error) {
+	return m.callImage("Magik", url)
+}
+
+// FivegOneg records the call and returns the result set with ReturnImage
+func (m *Mock) FivegOneg(url1 string, url2 string) ([]byte, error) {
+	return m.callImage("FivegOneg", url1, url2)
+}
+
+// WhyAreYouGay records the call and returns the result set with ReturnImage
+func (m *Mock) WhyAreYouGay(url1 string, url2 string) ([]byte, error) {
+	return m.callImage("WhyAreYouGay", url1, url2)
+}
+
+// Slap records the call and returns the result set with ReturnImage
+func (m *Mock) Slap(url1 string, url2 string) ([]byte, error) {
+	return m.callImage("Slap", url1, url2)
+}
+
+// Obama records the call and returns the result set with ReturnImage
+func (m *Mock) Obama(url1 string, url2 string) ([]byte, error) {
+	return m.callImage("Obama", url1, url2)
+}
+
+// Tweet records the call and returns the result set with ReturnImage
+func (m *Mock) Tweet(url string, username string, text string) ([]byte, error) {
+	return m.callImage("Tweet", url, username, text)
+}
+
+// YouTubeComment records the call and returns the result set with ReturnImage
+func (m *Mock) YouTubeComment(url string, username string, text string, darkMode bool) ([]byte, error) {
+	return m.callImage("YouTubeComment", url, username, text, darkMode)
+}
+
+// Discord records the call and returns the result set with ReturnImage
+func (m *Mock) Discord(url string, username string, text string, darkMode bool) ([]byte, error) {
+	return m.callImage("Discord", url, username, text, darkMode)
+}
+
+// Retromeme records the call and returns the result set with ReturnImage
+func (m *Mock) Retromeme(url string, topText string, bottomText string) ([]byte, error) {
+	return m.callImage("Retromeme", url, topText, bottomText)
+}
+
+// Motivational records the call and returns the result set with ReturnImage
+func (m *Mock) Motivational(url string, topText string, bottomText string) ([]byte, error) {
+	return m.callImage("Motivational", url, topText, bottomText)
+}
+
+// Modernmeme records the call and returns the result set with ReturnImage
+func (m *Mock) Modernmeme(url string, text string) ([]byte, error) {
+	return m.callImage("Modernmeme", url, text)
+}
+
+// Elmo records the call and returns the result set with ReturnImage
+func (m *Mock) Elmo(url string) ([]byte, error) {
+	return m.callImage("Elmo", url)
+}
+
+// TvStatic records the call and returns the result set with ReturnImage
+func (m *Mock) TvStatic(url string) ([]byte, error) {
+	return m.callImage("TvStatic", url)
+}
+
+// Rain records the call and returns the result set with ReturnImage
+func (m *Mock) Rain(url string) ([]byte, error) {
+	return m.callImage("Rain", url)
+}
+
+// Glitch records the call and returns the result set with ReturnImage
+func (m *Mock) Glitch(url string) ([]byte, error) {
+	return m.callImage("Glitch", url)
+}
+
+// GlitchStatic records the call and returns the result set with ReturnImage
+func (m *Mock) GlitchStatic(url string) ([]byte, error) {
+	return m.callImage("GlitchStatic", url)
+}
+
+// Album records the call and returns the result set with ReturnImage
+func (m *Mock) Album(url string) ([]byte, error) {
+	return m.callImage("Album", url)
+}
+
+//endregion
@@ -0,0 +1,170 @@
+// Package dagpimock is a programmable dagpi.DagpiAPI for unit tests. It records every call and answers with the
+// results configured per method, so command handlers can be tested without a server.
+package dagpimock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/beamer64/godagpi/dagpi"
+)
+
+// ErrNotConfigured is returned by methods that were given no result
+var ErrNotConfigured = errors.New("no result configured for method")
+
+// DataFunc answers a Data method, args are the arguments it was called with
+type DataFunc func(args ...interface{}) (interface{}, error)
+
+// ImageFunc answers an Image method, args are the arguments it was called with
+type ImageFunc func(args ...interface{}) ([]byte, error)
+
+// Call is a single recorded call
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Mock implements dagpi.DagpiAPI. The zero value answers every call with ErrNotConfigured.
+type Mock struct {
+	// DefaultImage, when set, is returned by Image methods without a configured result
+	DefaultImage []byte
+
+	mu         sync.Mutex
+	calls      []Call
+	dataFuncs  map[string]DataFunc
+	imageFuncs map[string]ImageFunc
+}
+
+var _ dagpi.DagpiAPI = (*Mock)(nil)
+
+var api = reflect.TypeOf((*dagpi.DagpiAPI)(nil)).Elem()
+
+// mustReturn panics if method isn't a DagpiAPI method returning result, so typos fail loudly instead of never matching
+func mustReturn(method string, result reflect.Type) {
+	m, ok := api.MethodByName(method)
+	if !ok {
+		panic(fmt.Sprintf("dagpimock: %s is not a DagpiAPI method", method))
+	}
+	if m.Type.Out(0) != result {
+		panic(fmt.Sprintf("dagpimock: %s does not return %s", method, result))
+	}
+}
+
+var (
+	dataType  = reflect.TypeOf((*interface{})(nil)).Elem()
+	imageType = reflect.TypeOf([]byte(nil))
+)
+
+// HandleData answers a Data method, like "Joke", with fn
+func (m *Mock) HandleData(method string, fn DataFunc) {
+	mustReturn(method, dataType)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dataFuncs == nil {
+		m.dataFuncs = map[string]DataFunc{}
+	}
+	m.dataFuncs[method] = fn
+}
+
+// ReturnData makes a Data method always return value and err
+func (m *Mock) ReturnData(method string, value interface{}, err error) {
+	m.HandleData(method, func(args ...interface{}) (interface{}, error) {
+		return value, err
+	})
+}
+
+// HandleImage answers an Image method, like "Wanted", with fn
+func (m *Mock) HandleImage(method string, fn ImageFunc) {
+	mustReturn(method, imageType)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.imageFuncs == nil {
+		m.imageFuncs = map[string]ImageFunc{}
+	}
+	m.imageFuncs[method] = fn
+}
+
+// ReturnImage makes an Image method always return data and err
+func (m *Mock) ReturnImage(method string, data []byte, err error) {
+	m.HandleImage(method, func(args ...interface{}) ([]byte, error) {
+		return data, err
+	})
+}
+
+// Calls returns every call made so far in order
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made to method
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets recorded calls and configured results
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+	m.dataFuncs = nil
+	m.imageFuncs = nil
+}
+
+func (m *Mock) record(method string, args []interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *Mock) callData(method string, args ...interface{}) (interface{}, error) {
+	m.record(method, args)
+
+	m.mu.Lock()
+	fn := m.dataFuncs[method]
+	m.mu.Unlock()
+
+	if fn == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotConfigured, method)
+	}
+
+	return fn(args...)
+}
+
+func (m *Mock) callImage(method string, args ...interface{}) ([]byte, error) {
+	m.record(method, args)
+
+	m.mu.Lock()
+	fn := m.imageFuncs[method]
+	defaultImage := m.DefaultImage
+	m.mu.Unlock()
+
+	if fn != nil {
+		return fn(args...)
+	}
+	if defaultImage != nil {
+		return defaultImage, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNotConfigured, method)
+}
@@ -0,0 +1,137 @@
+package dagpimock_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpimock"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// wantedPoster is the kind of handler the mock is for, it only knows about dagpi.DagpiAPI
+func wantedPoster(api dagpi.DagpiAPI, avatar string) ([]byte, error) {
+	if _, err := api.Joke(); err != nil {
+		return nil, err
+	}
+
+	return api.Wanted(avatar)
+}
+
+func TestMockAnswers(t *testing.T) {
+	mock := &dagpimock.Mock{}
+	if _, err := mock.Joke(); !errors.Is(err, dagpimock.ErrNotConfigured) {
+		t.Errorf("Joke() = %v, want ErrNotConfigured", err)
+	}
+	if _, err := mock.Wanted("a.png"); !errors.Is(err, dagpimock.ErrNotConfigured) {
+		t.Errorf("Wanted() = %v, want ErrNotConfigured", err)
+	}
+
+	joke := map[string]interface{}{"joke": "knock knock"}
+	mock.ReturnData("Joke", joke, nil)
+	if got, err := mock.Joke(); err != nil || !reflect.DeepEqual(got, joke) {
+		t.Errorf("Joke() = %v, %v, want %v", got, err, joke)
+	}
+
+	down := errors.New("down")
+	mock.ReturnImage("Wanted", nil, down)
+	if _, err := mock.Wanted("a.png"); !errors.Is(err, down) {
+		t.Errorf("Wanted() = %v, want the configured error", err)
+	}
+
+	mock.DefaultImage = []byte("default")
+	if got, err := mock.Invert("a.png"); err != nil || string(got) != "default" {
+		t.Errorf("Invert() = %q, %v, want the default image", got, err)
+	}
+	if _, err := mock.Wanted("a.png"); !errors.Is(err, down) {
+		t.Errorf("Wanted() = %v, want its own result over the default image", err)
+	}
+}
+
+func TestMockHandlers(t *testing.T) {
+	mock := &dagpimock.Mock{}
+	mock.HandleImage("Tweet", func(args ...interface{}) ([]byte, error) {
+		return []byte(args[1].(string) + ": " + args[2].(string)), nil
+	})
+	mock.HandleData("Waifu", func(args ...interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+
+	if got, _ := mock.Tweet("a.png", "dagpi", "hello"); string(got) != "dagpi: hello" {
+		t.Errorf("Tweet() = %q, want it built from the arguments", got)
+	}
+	if got, _ := mock.Waifu("rem"); got != "rem" {
+		t.Errorf("Waifu() = %v, want rem", got)
+	}
+}
+
+func TestMockCalls(t *testing.T) {
+	mock := &dagpimock.Mock{DefaultImage: []byte("image")}
+	_, _ = mock.Pixelate("a.png")
+	_, _ = mock.Joke()
+	_, _ = mock.Pixelate("b.png")
+	_, _ = mock.YouTubeComment("c.png", "dagpi", "first", true)
+
+	calls := mock.Calls()
+	if len(calls) != 4 || calls[1].Method != "Joke" {
+		t.Fatalf("Calls() = %v, want 4 calls in order", calls)
+	}
+	if want := []interface{}{"c.png", "dagpi", "first", true}; !reflect.DeepEqual(calls[3].Args, want) {
+		t.Errorf("YouTubeComment args = %v, want %v", calls[3].Args, want)
+	}
+
+	pixelate := mock.CallsTo("Pixelate")
+	if len(pixelate) != 2 || pixelate[1].Args[0] != "b.png" {
+		t.Errorf("CallsTo(Pixelate) = %v, want both calls", pixelate)
+	}
+
+	mock.ReturnData("Joke", "joke", nil)
+	mock.Reset()
+	if len(mock.Calls()) != 0 {
+		t.Error("Reset() kept the calls")
+	}
+	if _, err := mock.Joke(); !errors.Is(err, dagpimock.ErrNotConfigured) {
+		t.Errorf("Joke() after Reset() = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestMockRejectsUnknownMethods(t *testing.T) {
+	for name, configure := range map[string]func(*dagpimock.Mock){
+		"typo":       func(m *dagpimock.Mock) { m.ReturnData("Jokes", nil, nil) },
+		"image data": func(m *dagpimock.Mock) { m.ReturnData("Wanted", nil, nil) },
+		"data image": func(m *dagpimock.Mock) { m.ReturnImage("Joke", nil, nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("configuring the method didn't panic")
+				}
+			}()
+			configure(&dagpimock.Mock{})
+		})
+	}
+}
+
+func TestMockStandsInForClient(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	avatar := server.URL + "/assets/avatar.png"
+
+	remote, err := wantedPoster(server.Client(), avatar)
+	if err != nil {
+		t.Fatalf("wantedPoster() with the client = %v", err)
+	}
+
+	mock := &dagpimock.Mock{}
+	mock.ReturnData("Joke", map[string]interface{}{"joke": "knock knock"}, nil)
+	mock.ReturnImage("Wanted", remote, nil)
+	got, err := wantedPoster(mock, avatar)
+	if err != nil || !bytes.Equal(got, remote) {
+		t.Errorf("wantedPoster() with the mock = %v, want the configured image", err)
+	}
+	if calls := mock.CallsTo("Wanted"); len(calls) != 1 || calls[0].Args[0] != avatar {
+		t.Errorf("CallsTo(Wanted) = %v, want one call with the avatar", calls)
+	}
+}
@@ -0,0 +1,284 @@
+package dagpitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+)
+
+// Chaos is how often each kind of failure is injected, probabilities are between 0 and 1
+type Chaos struct {
+	// Latency is added to a request with LatencyProbability, 0 adds it to every request
+	Latency            time.Duration
+	LatencyProbability float64
+
+	// Reset fails the request with a connection reset before it is sent
+	Reset float64
+
+	// RateLimit starts a burst of RateLimitBurst 429 responses, one by default
+	RateLimit      float64
+	RateLimitBurst int
+
+	// ServerError answers with a 500, 502 or 503
+	ServerError float64
+
+	// Truncate cuts the body off halfway, reading it fails with io.ErrUnexpectedEOF
+	Truncate float64
+
+	// MalformedJSON cuts json bodies off halfway but reads them without error, so decoding fails.
+	// Bodies that aren't json are left alone.
+	MalformedJSON float64
+}
+
+// kinds of injected failures, as counted by ChaosTransport.Injected
+const (
+	InjectedLatency       = "latency"
+	InjectedReset         = "reset"
+	InjectedRateLimit     = "rate limit"
+	InjectedServerError   = "server error"
+	InjectedTruncate      = "truncate"
+	InjectedMalformedJSON = "malformed json"
+)
+
+// ChaosTransport is an http.RoundTripper that injects failures into requests.
+// The same seed and the same sequence of requests always inject the same failures.
+// The zero value passes every request through until Chaos is set, and rolls as if created with seed 0.
+type ChaosTransport struct {
+	// Transport sends the requests that aren't failed outright, defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	// Chaos applies to every route without its own entry in Routes
+	Chaos Chaos
+
+	// Routes overrides Chaos per route, keyed by path like "data/joke" or "image/pixel"
+	Routes map[string]Chaos
+
+	// Clock waits out injected latency, defaults to dagpi.SystemClock. Share a Clock with the client to test
+	// latency budgets without waiting.
+	Clock dagpi.Clock
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	bursts   map[string]int
+	injected map[string]int
+}
+
+// NewChaosTransport creates a ChaosTransport injecting chaos into every route
+func NewChaosTransport(seed int64, chaos Chaos) *ChaosTransport {
+	return &ChaosTransport{
+		Chaos:    chaos,
+		Routes:   map[string]Chaos{},
+		rng:      rand.New(rand.NewSource(seed)),
+		bursts:   map[string]int{},
+		injected: map[string]int{},
+	}
+}
+
+// lazyInit sets up the state NewChaosTransport would have for a zero value ChaosTransport. t.mu must be held.
+func (t *ChaosTransport) lazyInit() {
+	if t.rng == nil {
+		t.rng = rand.New(rand.NewSource(0))
+	}
+	if t.bursts == nil {
+		t.bursts = map[string]int{}
+	}
+	if t.injected == nil {
+		t.injected = map[string]int{}
+	}
+}
+
+// HTTPClient returns an http.Client using the transport, for dagpi.Client.HTTPClient
+func (t *ChaosTransport) HTTPClient() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Injected returns how many failures of each kind were injected
+func (t *ChaosTransport) Injected() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	injected := make(map[string]int, len(t.injected))
+	for kind, count := range t.injected {
+		injected[kind] = count
+	}
+
+	return injected
+}
+
+// plan is every decision for one request, rolled together so the sequence of random numbers doesn't depend on the responses
+type plan struct {
+	latency       time.Duration
+	reset         bool
+	rateLimit     bool
+	serverError   int
+	truncate      bool
+	malformedJSON bool
+}
+
+func (t *ChaosTransport) plan(path string) plan {
+	chaos, ok := t.Routes[path]
+	if !ok {
+		chaos = t.Chaos
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lazyInit()
+
+	roll := func(probability float64) bool {
+		return t.rng.Float64() < probability
+	}
+
+	var p plan
+	if chaos.Latency > 0 && (chaos.LatencyProbability == 0 || roll(chaos.LatencyProbability)) {
+		p.latency = chaos.Latency
+		t.injected[InjectedLatency]++
+	}
+
+	reset, rateLimit, serverError := roll(chaos.Reset), roll(chaos.RateLimit), roll(chaos.ServerError)
+	statuses := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+	status := statuses[t.rng.Intn(len(statuses))]
+	truncate, malformedJSON := roll(chaos.Truncate), roll(chaos.MalformedJSON)
+
+	switch {
+	case t.bursts[path] > 0:
+		t.bursts[path]--
+		p.rateLimit = true
+	case reset:
+		p.reset = true
+	case rateLimit:
+		p.rateLimit = true
+		burst := chaos.RateLimitBurst
+		if burst < 1 {
+			burst = 1
+		}
+		t.bursts[path] = burst - 1
+	case serverError:
+		p.serverError = status
+	case truncate:
+		p.truncate = true
+	case malformedJSON:
+		p.malformedJSON = true
+	}
+
+	for kind, injected := range map[string]bool{
+		InjectedReset:       p.reset,
+		InjectedRateLimit:   p.rateLimit,
+		InjectedServerError: p.serverError != 0,
+	} {
+		if injected {
+			t.injected[kind]++
+		}
+	}
+
+	return p
+}
+
+func (t *ChaosTransport) count(kind string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lazyInit()
+
+	t.injected[kind]++
+}
+
+// RoundTrip sends req, injecting failures according to the route's Chaos
+func (t *ChaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.plan(strings.Trim(req.URL.Path, "/"))
+
+	if p.latency > 0 {
+		clock := t.Clock
+		if clock == nil {
+			clock = dagpi.SystemClock
+		}
+		timer := clock.NewTimer(p.latency)
+		select {
+		case <-timer.C():
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	switch {
+	case p.reset:
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	case p.rateLimit:
+		return chaosResponse(req, http.StatusTooManyRequests, "Too Many Requests"), nil
+	case p.serverError != 0:
+		return chaosResponse(req, p.serverError, http.StatusText(p.serverError)), nil
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil || (!p.truncate && !p.malformedJSON) {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case p.truncate:
+		t.count(InjectedTruncate)
+		resp.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body[:len(body)/2]), errReader{io.ErrUnexpectedEOF}))
+	case p.malformedJSON && mediaType == "application/json":
+		t.count(InjectedMalformedJSON)
+		body = body[:len(body)/2]
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Set("Content-Length", fmt.Sprint(len(body)))
+	default:
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func chaosResponse(req *http.Request, status int, message string) *http.Response {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	if status == http.StatusTooManyRequests {
+		resp.Header.Set("Retry-After", "1")
+	}
+
+	return resp
+}
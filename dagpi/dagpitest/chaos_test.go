@@ -0,0 +1,159 @@
+package dagpitest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// chaosClient is a client of server that sends its requests through chaos
+func chaosClient(server *dagpitest.Server, chaos *dagpitest.ChaosTransport) *dagpi.Client {
+	client := server.Client()
+	client.HTTPClient = chaos.HTTPClient()
+
+	return client
+}
+
+func TestChaosZeroValue(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	chaos := &dagpitest.ChaosTransport{}
+	client := chaosClient(server, chaos)
+
+	if _, err := client.Joke(); err != nil {
+		t.Fatalf("Joke() through a zero ChaosTransport = %v", err)
+	}
+	if len(chaos.Injected()) != 0 {
+		t.Errorf("Injected() = %v, want nothing", chaos.Injected())
+	}
+
+	chaos.Chaos = dagpitest.Chaos{ServerError: 1}
+	if _, err := client.Joke(); apiStatus(err) < 500 {
+		t.Errorf("Joke() = %v, want a 5xx", err)
+	}
+	if chaos.Injected()[dagpitest.InjectedServerError] != 1 {
+		t.Errorf("Injected() = %v, want one server error", chaos.Injected())
+	}
+}
+
+func TestChaosIsDeterministic(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	outcomes := func() []int {
+		chaos := dagpitest.NewChaosTransport(7, dagpitest.Chaos{RateLimit: 0.2, ServerError: 0.2, Reset: 0.2})
+		client := chaosClient(server, chaos)
+
+		var statuses []int
+		for i := 0; i < 30; i++ {
+			_, err := client.Fact()
+			switch {
+			case err == nil:
+				statuses = append(statuses, http.StatusOK)
+			case errors.Is(err, syscall.ECONNRESET):
+				statuses = append(statuses, -1)
+			default:
+				statuses = append(statuses, apiStatus(err))
+			}
+		}
+		return statuses
+	}
+
+	first, second := outcomes(), outcomes()
+	failed := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("request %d was %d then %d with the same seed", i, first[i], second[i])
+		}
+		if first[i] != http.StatusOK {
+			failed++
+		}
+	}
+	if failed == 0 || failed == len(first) {
+		t.Errorf("%d of %d requests failed, want some of each", failed, len(first))
+	}
+}
+
+func TestChaosRateLimitBurst(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	chaos := dagpitest.NewChaosTransport(1, dagpitest.Chaos{})
+	chaos.Routes["data/joke"] = dagpitest.Chaos{RateLimit: 1, RateLimitBurst: 3}
+	client := chaosClient(server, chaos)
+
+	if _, err := client.Joke(); apiStatus(err) != http.StatusTooManyRequests {
+		t.Fatalf("Joke() = %v, want a 429", err)
+	}
+	if _, err := client.Fact(); err != nil {
+		t.Errorf("Fact() = %v, want only data/joke limited", err)
+	}
+
+	// the burst keeps going once started
+	chaos.Routes["data/joke"] = dagpitest.Chaos{}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Joke(); apiStatus(err) != http.StatusTooManyRequests {
+			t.Errorf("Joke() %d into the burst = %v, want a 429", i+2, err)
+		}
+	}
+	if _, err := client.Joke(); err != nil {
+		t.Errorf("Joke() after the burst = %v", err)
+	}
+	if server.Requests("data/joke") != 1 {
+		t.Errorf("the server got %d joke requests, want the limited ones kept from it", server.Requests("data/joke"))
+	}
+}
+
+func TestChaosBodies(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	truncated := chaosClient(server, dagpitest.NewChaosTransport(1, dagpitest.Chaos{Truncate: 1}))
+	if _, err := truncated.Pixelate(input); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Pixelate() with a truncated body = %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	malformed := dagpitest.NewChaosTransport(1, dagpitest.Chaos{MalformedJSON: 1})
+	client := chaosClient(server, malformed)
+	var syntaxErr *json.SyntaxError
+	if _, err := client.Joke(); !errors.As(err, &syntaxErr) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Joke() with malformed json = %v, want a decoding error", err)
+	}
+	if _, err := client.Pixelate(input); err != nil {
+		t.Errorf("Pixelate() = %v, want images left alone by MalformedJSON", err)
+	}
+	if malformed.Injected()[dagpitest.InjectedMalformedJSON] != 1 {
+		t.Errorf("Injected() = %v, want one malformed json", malformed.Injected())
+	}
+}
+
+func TestChaosLatency(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	clock := dagpitest.NewClock(time.Time{})
+	chaos := dagpitest.NewChaosTransport(1, dagpitest.Chaos{Latency: time.Hour})
+	chaos.Clock = clock
+	client := chaosClient(server, chaos)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Joke()
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	if server.Requests("") != 0 {
+		t.Error("the request was sent before the latency passed")
+	}
+	clock.Advance(time.Hour)
+	if err := <-done; err != nil {
+		t.Errorf("Joke() = %v", err)
+	}
+}
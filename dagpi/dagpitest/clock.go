@@ -0,0 +1,119 @@
+package dagpitest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+)
+
+// Clock is a dagpi.Clock that only moves when told to, so rate limits, cache expiry, the circuit breaker and
+// latency budgets can be tested without waiting. It is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	added  chan struct{}
+}
+
+// NewClock creates a Clock stopped at start, a fixed date when start is zero
+func NewClock(start time.Time) *Clock {
+	if start.IsZero() {
+		start = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return &Clock{now: start, added: make(chan struct{}, 1)}
+}
+
+var _ dagpi.Clock = (*Clock)(nil)
+
+// Now returns the clock's current time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer returns a timer that fires once the clock is advanced past d from now
+func (c *Clock) NewTimer(d time.Duration) dagpi.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, when: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+
+	select {
+	case c.added <- struct{}{}:
+	default:
+	}
+
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that comes due in order
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].when.Before(c.timers[j].when) })
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- t.when
+	}
+	c.timers = pending
+}
+
+// Timers returns how many timers are waiting to fire
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are waiting, which is how a test knows that code running in another
+// goroutine has started waiting on the clock and Advance will wake it
+func (c *Clock) BlockUntil(n int) {
+	for c.Timers() < n {
+		select {
+		case <-c.added:
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+type fakeTimer struct {
+	clock *Clock
+	when  time.Time
+	c     chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package dagpitest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func fired(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestClockAdvance(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	start := clock.Now()
+	if start.IsZero() {
+		t.Fatal("Now() is zero, want the fixed start date")
+	}
+
+	soon, later := clock.NewTimer(time.Second), clock.NewTimer(time.Minute)
+	if clock.Timers() != 2 {
+		t.Fatalf("Timers() = %d, want 2", clock.Timers())
+	}
+
+	clock.Advance(time.Second)
+	if !fired(soon.C()) || fired(later.C()) {
+		t.Error("a second in, want only the one second timer fired")
+	}
+	if !clock.Now().Equal(start.Add(time.Second)) {
+		t.Errorf("Now() = %v, want a second after the start", clock.Now())
+	}
+
+	clock.Advance(time.Hour)
+	select {
+	case at := <-later.C():
+		if !at.Equal(start.Add(time.Minute)) {
+			t.Errorf("the minute timer fired at %v, want the time it was due", at)
+		}
+	default:
+		t.Error("the minute timer didn't fire an hour in")
+	}
+	if clock.Timers() != 0 {
+		t.Errorf("Timers() = %d after every timer fired, want 0", clock.Timers())
+	}
+}
+
+func TestClockTimers(t *testing.T) {
+	clock := dagpitest.NewClock(time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC))
+	if clock.Now().Year() != 2030 {
+		t.Errorf("Now() = %v, want the start it was given", clock.Now())
+	}
+
+	if !fired(clock.NewTimer(0).C()) {
+		t.Error("a timer for 0 didn't fire at once")
+	}
+
+	stopped := clock.NewTimer(time.Second)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Stop() should report true once and false after")
+	}
+	clock.Advance(time.Second)
+	if fired(stopped.C()) {
+		t.Error("a stopped timer fired")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		<-clock.NewTimer(time.Minute).C()
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+}
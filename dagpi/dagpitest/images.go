@@ -0,0 +1,68 @@
+package dagpitest
+
+import (
+	"bytes"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+)
+
+const (
+	placeholderSize   = 128
+	placeholderFrames = 6
+)
+
+// placeholderColor picks a stable color per name so different routes give visibly different images
+func placeholderColor(name string) color.RGBA {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	sum := h.Sum32()
+
+	return color.RGBA{R: uint8(sum), G: uint8(sum >> 8), B: uint8(sum >> 16), A: 255}
+}
+
+// drawPlaceholder fills a frame with the name's color and a diagonal stripe moved along by offset
+func drawPlaceholder(img interface{ Set(x, y int, c color.Color) }, c color.RGBA, offset int) {
+	stripe := color.RGBA{R: 255 - c.R, G: 255 - c.G, B: 255 - c.B, A: 255}
+	for y := 0; y < placeholderSize; y++ {
+		for x := 0; x < placeholderSize; x++ {
+			if (x+y+offset)%64 < 16 {
+				img.Set(x, y, stripe)
+			} else {
+				img.Set(x, y, c)
+			}
+		}
+	}
+}
+
+// PlaceholderPNG returns the png the server answers with for name
+func PlaceholderPNG(name string) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, placeholderSize, placeholderSize))
+	drawPlaceholder(img, placeholderColor(name), 0)
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+
+	return buf.Bytes()
+}
+
+// PlaceholderGIF returns the animated gif the server answers with for name
+func PlaceholderGIF(name string) []byte {
+	c := placeholderColor(name)
+	palette := color.Palette{c, color.RGBA{R: 255 - c.R, G: 255 - c.G, B: 255 - c.B, A: 255}}
+
+	anim := &gif.GIF{}
+	for i := 0; i < placeholderFrames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, placeholderSize, placeholderSize), palette)
+		drawPlaceholder(frame, c, i*64/placeholderFrames)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 8)
+	}
+
+	var buf bytes.Buffer
+	_ = gif.EncodeAll(&buf, anim)
+
+	return buf.Bytes()
+}
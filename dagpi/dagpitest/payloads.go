@@ -0,0 +1,142 @@
+package dagpitest
+
+// baseURL is replaced with the server's url in payloads so image links point back at it
+const baseURL = "{{base}}"
+
+type payload map[string]interface{}
+
+// payloads are answered in turn for each data route
+var payloads = map[string][]payload{
+	"data/wtp": {
+		{
+			"Data": payload{
+				"abilities": []string{"Overgrow", "Chlorophyll"},
+				"ascii":     "Bulbasaur",
+				"height":    0.7,
+				"id":        1,
+				"link":      "https://pokemondb.net/pokedex/bulbasaur",
+				"name":      "Bulbasaur",
+				"Type":      []string{"Grass", "Poison"},
+				"weight":    6.9,
+			},
+			"question": baseURL + "/assets/wtp/1/question.png",
+			"answer":   baseURL + "/assets/wtp/1/answer.png",
+		},
+		{
+			"Data": payload{
+				"abilities": []string{"Static", "Lightning-rod"},
+				"ascii":     "Pikachu",
+				"height":    0.4,
+				"id":        25,
+				"link":      "https://pokemondb.net/pokedex/pikachu",
+				"name":      "Pikachu",
+				"Type":      []string{"Electric"},
+				"weight":    6,
+			},
+			"question": baseURL + "/assets/wtp/25/question.png",
+			"answer":   baseURL + "/assets/wtp/25/answer.png",
+		},
+	},
+	"data/roast": {
+		{"roast": "You're the reason the gene pool needs a lifeguard."},
+		{"roast": "I'd agree with you, but then we'd both be wrong."},
+	},
+	"data/joke": {
+		{"id": "HZR9QXoWFp", "joke": "Why don't skeletons fight each other? They don't have the guts."},
+		{"id": "eN8Pd9mKzT", "joke": "I told my wife she was drawing her eyebrows too high. She looked surprised."},
+	},
+	"data/fact": {
+		{"fact": "Honey never spoils, edible honey has been found in ancient Egyptian tombs."},
+		{"fact": "Octopuses have three hearts."},
+	},
+	"data/8ball": {
+		{"response": "It is certain."},
+		{"response": "Reply hazy, try again."},
+		{"response": "Don't count on it."},
+	},
+	"data/yomama": {
+		{"description": "Yo mama so old, her birth certificate says expired."},
+		{"description": "Yo mama so slow, it took her two hours to watch 60 Minutes."},
+	},
+	"data/waifu": {
+		{
+			"id":              2,
+			"name":            "Rem",
+			"original_name":   "レム",
+			"description":     "Rem is a maid working in the Roswaal Mansion along with her older twin sister Ram.",
+			"display_picture": baseURL + "/assets/waifu/2.png",
+			"url":             "https://mywaifulist.moe/waifu/rem",
+			"series":          payload{"name": "Re:Zero kara Hajimeru Isekai Seikatsu"},
+			"likes":           5321,
+			"trash":           204,
+			"husbando":        false,
+			"nsfw":            false,
+		},
+	},
+	"data/pickupline": {
+		{"category": "cheesy", "joke": "Are you a magician? Because whenever I look at you, everyone else disappears."},
+		{"category": "nerdy", "joke": "Are you made of copper and tellurium? Because you're Cu-Te."},
+	},
+	"data/headline": {
+		{"text": "Local Man Discovers He Has Been Using Stairs Wrong His Entire Life", "fake": true},
+		{"text": "City Council Approves New Bike Lanes Downtown", "fake": false},
+	},
+	"data/logo": {
+		{
+			"question": baseURL + "/assets/logo/github/question.png",
+			"answer":   baseURL + "/assets/logo/github/answer.png",
+			"brand":    "GitHub",
+			"clue":     "Where developers host their code",
+			"hint":     "G_t_u_",
+			"easy":     false,
+			"wiki_url": "https://en.wikipedia.org/wiki/GitHub",
+		},
+	},
+	"data/flag": {
+		{
+			"Data": payload{
+				"name":    payload{"common": "Japan", "official": "Japan"},
+				"cca2":    "JP",
+				"cca3":    "JPN",
+				"capital": []string{"Tokyo"},
+				"region":  "Asia",
+			},
+			"flag": baseURL + "/assets/flag/jp.png",
+		},
+		{
+			"Data": payload{
+				"name":    payload{"common": "Canada", "official": "Canada"},
+				"cca2":    "CA",
+				"cca3":    "CAN",
+				"capital": []string{"Ottawa"},
+				"region":  "Americas",
+			},
+			"flag": baseURL + "/assets/flag/ca.png",
+		},
+	},
+	"data/captcha": {
+		{"image": baseURL + "/assets/captcha/x7kq2m.png", "answer": "x7kq2m"},
+		{"image": baseURL + "/assets/captcha/p3wz9a.png", "answer": "p3wz9a"},
+	},
+	"data/typeracer": {
+		{"image": baseURL + "/assets/typeracer/1.png", "sentence": "The quick brown fox jumps over the lazy dog."},
+		{"image": baseURL + "/assets/typeracer/2.png", "sentence": "Practice makes progress, not perfection."},
+	},
+}
+
+// waifuSearch is answered for data/<name>, which is what Client.Waifu requests
+func waifuSearch(name string) payload {
+	return payload{
+		"id":              1,
+		"name":            name,
+		"original_name":   name,
+		"description":     name + " is a character that matched the search.",
+		"display_picture": baseURL + "/assets/waifu/" + name + ".png",
+		"url":             "https://mywaifulist.moe/waifu/" + name,
+		"series":          payload{"name": "Placeholder Series"},
+		"likes":           100,
+		"trash":           10,
+		"husbando":        false,
+		"nsfw":            false,
+	}
+}
@@ -0,0 +1,269 @@
+// Package dagpitest runs a fake Dagpi API in process so code using dagpi.Client can be tested without
+// a token or network access. Every data and image route the client calls is served with canned payloads
+// and generated placeholder images. Errors can be injected per route with Fail, while SetLatency and RateLimit
+// apply to every route.
+package dagpitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/internal/routes"
+)
+
+// DefaultToken is the token the server accepts when NewServer is given none
+const DefaultToken = "dagpitest-token"
+
+// Fault is an error the server answers with instead of the normal response
+type Fault struct {
+	Status  int
+	Message string
+
+	// Times the fault is answered before the route recovers, zero keeps answering it
+	Times int
+}
+
+// Server is a fake Dagpi API
+type Server struct {
+	// URL of the server, use it as dagpi.Client.BaseURL
+	URL string
+
+	// Token is the only Authorization header accepted
+	Token string
+
+	// Clock waits out the latency set with SetLatency, defaults to dagpi.SystemClock
+	Clock dagpi.Clock
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	latency  time.Duration
+	faults   map[string]*Fault
+	requests map[string]int
+	served   map[string]int
+	images   map[string][]byte
+}
+
+// NewServer starts a fake API accepting token, DefaultToken when it is empty. Close it when done.
+func NewServer(token string) *Server {
+	if token == "" {
+		token = DefaultToken
+	}
+
+	s := &Server{
+		Token:    token,
+		faults:   map[string]*Fault{},
+		requests: map[string]int{},
+		served:   map[string]int{},
+		images:   map[string][]byte{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a dagpi.Client pointed at the server with a valid token
+func (s *Server) Client() *dagpi.Client {
+	return &dagpi.Client{Auth: s.Token, BaseURL: s.URL}
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// Fail makes a route answer with fault, path is like "data/joke" or "image/pixel" and "" fails every route.
+// A route's own fault takes priority over the one for every route.
+func (s *Server) Fail(path string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[strings.Trim(path, "/")] = &fault
+}
+
+// RateLimit answers the next n requests to any route with 429. Fail with a 429 Fault limits a single route.
+func (s *Server) RateLimit(n int) {
+	s.Fail("", Fault{Status: http.StatusTooManyRequests, Message: "Too Many Requests", Times: n})
+}
+
+// Requests returns how many requests with a valid token were made to path, "" counts every route
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if path == "" {
+		total := 0
+		for _, count := range s.requests {
+			total += count
+		}
+		return total
+	}
+
+	return s.requests[strings.Trim(path, "/")]
+}
+
+// Reset clears faults, latency and request counts
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = 0
+	s.faults = map[string]*Fault{}
+	s.requests = map[string]int{}
+	s.served = map[string]int{}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	writeJSON(w, status, body)
+}
+
+func writeImage(w http.ResponseWriter, contentType string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+
+	// images linked from data payloads, served without a token like the real image host
+	if strings.HasPrefix(path, "assets/") {
+		writeImage(w, "image/png", s.image(path, false))
+		return
+	}
+
+	// rejected before counting or taking a fault, so a bad token doesn't use up a fault meant for the client
+	if r.Header.Get("Authorization") != s.Token {
+		writeMessage(w, http.StatusForbidden, "Invalid token")
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[path]++
+	latency := s.latency
+	fault := s.takeFault(path)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		clock := s.Clock
+		if clock == nil {
+			clock = dagpi.SystemClock
+		}
+		timer := clock.NewTimer(latency)
+		select {
+		case <-timer.C():
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	if fault != nil {
+		writeMessage(w, fault.Status, fault.Message)
+		return
+	}
+
+	route, ok := routes.Find(path)
+	switch {
+	case ok && route.IsImage():
+		s.serveImage(w, r, route)
+	case ok:
+		s.serveData(w, path, payloads[path])
+	case strings.HasPrefix(path, "data/") && !strings.Contains(path[len("data/"):], "/"):
+		s.serveData(w, path, []payload{waifuSearch(path[len("data/"):])})
+	default:
+		writeMessage(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// takeFault returns the fault to answer path with, if any. s.mu must be held.
+func (s *Server) takeFault(path string) *Fault {
+	fault, ok := s.faults[path]
+	key := path
+	if !ok {
+		fault, ok = s.faults[""]
+		key = ""
+	}
+	if !ok {
+		return nil
+	}
+
+	if fault.Times > 0 {
+		fault.Times--
+		if fault.Times == 0 {
+			delete(s.faults, key)
+		}
+	}
+
+	return fault
+}
+
+func (s *Server) serveData(w http.ResponseWriter, path string, pool []payload) {
+	s.mu.Lock()
+	next := pool[s.served[path]%len(pool)]
+	s.served[path]++
+	s.mu.Unlock()
+
+	body, err := json.Marshal(next)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, []byte(strings.ReplaceAll(string(body), baseURL, s.URL)))
+}
+
+func (s *Server) serveImage(w http.ResponseWriter, r *http.Request, route routes.Route) {
+	query := r.URL.Query()
+	for _, param := range route.Params {
+		if query.Get(param) == "" {
+			writeMessage(w, http.StatusBadRequest, "Missing parameter: "+param)
+			return
+		}
+	}
+
+	if route.Animated {
+		writeImage(w, "image/gif", s.image(route.Path, true))
+		return
+	}
+
+	writeImage(w, "image/png", s.image(route.Path, false))
+}
+
+// image returns the placeholder for name, generating it the first time
+func (s *Server) image(name string, animated bool) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if data, ok := s.images[name]; ok {
+		return data
+	}
+
+	data := PlaceholderPNG(name)
+	if animated {
+		data = PlaceholderGIF(name)
+	}
+	s.images[name] = data
+
+	return data
+}
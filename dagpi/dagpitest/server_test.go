@@ -0,0 +1,165 @@
+package dagpitest_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func apiStatus(err error) int {
+	var apiErr *dagpi.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+
+	return 0
+}
+
+func TestServerData(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	client := server.Client()
+
+	first, err := client.WTP()
+	if err != nil {
+		t.Fatalf("WTP() = %v", err)
+	}
+	second, _ := client.WTP()
+	if first.(map[string]interface{})["answer"] == second.(map[string]interface{})["answer"] {
+		t.Error("WTP() answered the same pokemon twice, want the payloads in turn")
+	}
+
+	// image links in payloads point back at the server and are served without a token
+	question := first.(map[string]interface{})["question"].(string)
+	if !strings.HasPrefix(question, server.URL) {
+		t.Fatalf("question = %s, want a link to %s", question, server.URL)
+	}
+	resp, err := http.Get(question)
+	if err != nil {
+		t.Fatalf("fetching the question: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, format, err := image.DecodeConfig(resp.Body); err != nil || format != "png" {
+		t.Errorf("the question is %s (%v), want a png", format, err)
+	}
+
+	if _, err = client.Waifu("rem"); err != nil {
+		t.Errorf("Waifu() = %v", err)
+	}
+}
+
+func TestServerImages(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	client := server.Client()
+	input := server.URL + "/assets/input.png"
+
+	for effect, want := range map[dagpi.Effect]string{dagpi.EffectPixelate: "png", dagpi.EffectTriggered: "gif"} {
+		img, err := client.ApplyImage(effect, input)
+		if err != nil {
+			t.Fatalf("ApplyImage(%s) = %v", effect, err)
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(img.Data)); err != nil || format != want {
+			t.Errorf("%s answered a %s (%v), want a %s", effect, format, err, want)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/image/pixel/", nil)
+	req.Header.Set("Authorization", server.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET image/pixel: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("image/pixel without a url answered %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	server := dagpitest.NewServer("secret")
+	defer server.Close()
+	server.RateLimit(1)
+
+	intruder := &dagpi.Client{Auth: "wrong", BaseURL: server.URL}
+	if _, err := intruder.Joke(); apiStatus(err) != http.StatusForbidden {
+		t.Fatalf("Joke() with a bad token = %v, want 403", err)
+	}
+	if server.Requests("") != 0 {
+		t.Errorf("Requests() = %d, want the rejected request left uncounted", server.Requests(""))
+	}
+
+	// the rate limit is still waiting for a client with the right token
+	client := server.Client()
+	if _, err := client.Joke(); apiStatus(err) != http.StatusTooManyRequests {
+		t.Errorf("Joke() = %v, want the 429 the bad token didn't use up", err)
+	}
+	if _, err := client.Joke(); err != nil {
+		t.Errorf("Joke() after the rate limit = %v", err)
+	}
+	if server.Requests("data/joke") != 2 {
+		t.Errorf("Requests(data/joke) = %d, want 2", server.Requests("data/joke"))
+	}
+}
+
+func TestServerFail(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	client := server.Client()
+
+	server.Fail("data/fact", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "down", Times: 2})
+	server.Fail("", dagpitest.Fault{Status: http.StatusBadGateway, Message: "everything is down"})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Fact(); apiStatus(err) != http.StatusInternalServerError {
+			t.Errorf("Fact() call %d = %v, want the route's own 500", i, err)
+		}
+	}
+	if _, err := client.Fact(); apiStatus(err) != http.StatusBadGateway {
+		t.Errorf("Fact() once its fault ran out = %v, want the 502 for every route", err)
+	}
+	if _, err := client.Joke(); apiStatus(err) != http.StatusBadGateway {
+		t.Errorf("Joke() = %v, want the 502 for every route", err)
+	}
+
+	server.Reset()
+	if _, err := client.Fact(); err != nil {
+		t.Errorf("Fact() after Reset() = %v", err)
+	}
+	if server.Requests("") != 1 {
+		t.Errorf("Requests() after Reset() = %d, want 1", server.Requests(""))
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	clock := dagpitest.NewClock(time.Time{})
+	server.Clock = clock
+	server.SetLatency(time.Minute)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := server.Client().Joke()
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("Joke() = %v before the latency passed", err)
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Errorf("Joke() = %v", err)
+	}
+}
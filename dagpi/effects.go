@@ -0,0 +1,95 @@
+package dagpi
+
+import "context"
+
+// Effect is the route of an image manipulation that only takes a single image url
+type Effect string
+
+// Every single image effect, named after the method that calls it
+const (
+	EffectPixelate     Effect = "pixel"
+	EffectMirror       Effect = "mirror"
+	EffectFlipImage    Effect = "flip"
+	EffectColors       Effect = "colors"
+	EffectAmerica      Effect = "america"
+	EffectCommunism    Effect = "communism"
+	EffectTriggered    Effect = "triggered"
+	EffectExpandImage  Effect = "expand"
+	EffectWasted       Effect = "wasted"
+	EffectSketch       Effect = "sketch"
+	EffectSpinImage    Effect = "spin"
+	EffectPetPet       Effect = "petpet"
+	EffectBonk         Effect = "bonk"
+	EffectBomb         Effect = "bomb"
+	EffectShake        Effect = "shake"
+	EffectInvert       Effect = "invert"
+	EffectSobel        Effect = "sobel"
+	EffectHog          Effect = "hog"
+	EffectTriangle     Effect = "triangle"
+	EffectBlur         Effect = "blur"
+	EffectRGB          Effect = "rgb"
+	EffectAngel        Effect = "angel"
+	EffectSatan        Effect = "satan"
+	EffectDelete       Effect = "delete"
+	EffectFedora       Effect = "fedora"
+	EffectHitler       Effect = "hitler"
+	EffectLego         Effect = "lego"
+	EffectWanted       Effect = "wanted"
+	EffectStringify    Effect = "stringify"
+	EffectBurn         Effect = "burn"
+	EffectEarth        Effect = "earth"
+	EffectFreeze       Effect = "freeze"
+	EffectGround       Effect = "ground"
+	EffectMosiac       Effect = "mosiac"
+	EffectSithlord     Effect = "sith"
+	EffectJail         Effect = "jail"
+	EffectShatter      Effect = "shatter"
+	EffectTrash        Effect = "trash"
+	EffectDeepfry      Effect = "deepfry"
+	EffectAscii        Effect = "ascii"
+	EffectCharcoal     Effect = "charcoal"
+	EffectPosterize    Effect = "poster"
+	EffectSepia        Effect = "sepia"
+	EffectSwirl        Effect = "swirl"
+	EffectPaint        Effect = "paint"
+	EffectNight        Effect = "night"
+	EffectRainbow      Effect = "rainbow"
+	EffectMagik        Effect = "magik"
+	EffectElmo         Effect = "elmo"
+	EffectTvStatic     Effect = "tv"
+	EffectRain         Effect = "rain"
+	EffectGlitch       Effect = "glitch"
+	EffectGlitchStatic Effect = "glitchstatic"
+	EffectAlbum        Effect = "album"
+)
+
+// Routes of the image manipulations that take more than an image url. They can't be used with Apply but
+// identify the route to fallback renderers and caches.
+const (
+	EffectPride          Effect = "pride"
+	EffectFivegOneg      Effect = "5g1g"
+	EffectWhyAreYouGay   Effect = "whyareyougay"
+	EffectSlap           Effect = "slap"
+	EffectObama          Effect = "obama"
+	EffectTweet          Effect = "tweet"
+	EffectYouTubeComment Effect = "yt"
+	EffectDiscord        Effect = "discord"
+	EffectRetromeme      Effect = "retromeme"
+	EffectMotivational   Effect = "motiv"
+	EffectModernmeme     Effect = "modernmeme"
+)
+
+// Apply runs any single image effect on an image, the same as calling its method
+func (c *Client) Apply(effect Effect, url string) ([]byte, error) {
+	return c.ApplyContext(context.Background(), effect, url)
+}
+
+// ApplyContext is Apply with a context that can cancel the request
+func (c *Client) ApplyContext(ctx context.Context, effect Effect, url string) ([]byte, error) {
+	buffer, err := getImageBufferContext(ctx, c.baseURL()+"/image/"+string(effect)+"/?url="+url, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
@@ -0,0 +1,155 @@
+package fakedata
+
+type pokemon struct {
+	id        int
+	name      string
+	types     []string
+	abilities []string
+	height    float64
+	weight    float64
+}
+
+var pokemons = []pokemon{
+	{1, "Bulbasaur", []string{"Grass", "Poison"}, []string{"Overgrow", "Chlorophyll"}, 0.7, 6.9},
+	{4, "Charmander", []string{"Fire"}, []string{"Blaze", "Solar-power"}, 0.6, 8.5},
+	{7, "Squirtle", []string{"Water"}, []string{"Torrent", "Rain-dish"}, 0.5, 9},
+	{12, "Butterfree", []string{"Bug", "Flying"}, []string{"Compound-eyes", "Tinted-lens"}, 1.1, 32},
+	{25, "Pikachu", []string{"Electric"}, []string{"Static", "Lightning-rod"}, 0.4, 6},
+	{35, "Clefairy", []string{"Fairy"}, []string{"Cute-charm", "Magic-guard"}, 0.6, 7.5},
+	{39, "Jigglypuff", []string{"Normal", "Fairy"}, []string{"Cute-charm", "Competitive"}, 0.5, 5.5},
+	{52, "Meowth", []string{"Normal"}, []string{"Pickup", "Technician"}, 0.4, 4.2},
+	{54, "Psyduck", []string{"Water"}, []string{"Damp", "Cloud-nine"}, 0.8, 19.6},
+	{63, "Abra", []string{"Psychic"}, []string{"Synchronize", "Inner-focus"}, 0.9, 19.5},
+	{66, "Machop", []string{"Fighting"}, []string{"Guts", "No-guard"}, 0.8, 19.5},
+	{74, "Geodude", []string{"Rock", "Ground"}, []string{"Rock-head", "Sturdy"}, 0.4, 20},
+	{92, "Gastly", []string{"Ghost", "Poison"}, []string{"Levitate"}, 1.3, 0.1},
+	{94, "Gengar", []string{"Ghost", "Poison"}, []string{"Cursed-body"}, 1.5, 40.5},
+	{95, "Onix", []string{"Rock", "Ground"}, []string{"Rock-head", "Sturdy"}, 8.8, 210},
+	{129, "Magikarp", []string{"Water"}, []string{"Swift-swim"}, 0.9, 10},
+	{130, "Gyarados", []string{"Water", "Flying"}, []string{"Intimidate"}, 6.5, 235},
+	{131, "Lapras", []string{"Water", "Ice"}, []string{"Water-absorb", "Shell-armor"}, 2.5, 220},
+	{133, "Eevee", []string{"Normal"}, []string{"Run-away", "Adaptability"}, 0.3, 6.5},
+	{143, "Snorlax", []string{"Normal"}, []string{"Immunity", "Thick-fat"}, 2.1, 460},
+	{147, "Dratini", []string{"Dragon"}, []string{"Shed-skin"}, 1.8, 3.3},
+	{149, "Dragonite", []string{"Dragon", "Flying"}, []string{"Inner-focus"}, 2.2, 210},
+	{150, "Mewtwo", []string{"Psychic"}, []string{"Pressure"}, 2, 122},
+	{152, "Chikorita", []string{"Grass"}, []string{"Overgrow"}, 0.9, 6.4},
+	{175, "Togepi", []string{"Fairy"}, []string{"Hustle", "Serene-grace"}, 0.3, 1.5},
+	{196, "Espeon", []string{"Psychic"}, []string{"Synchronize"}, 0.9, 26.5},
+	{197, "Umbreon", []string{"Dark"}, []string{"Synchronize"}, 1, 27},
+	{248, "Tyranitar", []string{"Rock", "Dark"}, []string{"Sand-stream"}, 2, 202},
+	{282, "Gardevoir", []string{"Psychic", "Fairy"}, []string{"Synchronize", "Trace"}, 1.6, 48.4},
+	{448, "Lucario", []string{"Fighting", "Steel"}, []string{"Steadfast", "Inner-focus"}, 1.2, 54},
+}
+
+var jokeSetups = []string{
+	"Why don't skeletons fight each other?",
+	"Why did the scarecrow win an award?",
+	"What do you call a fake noodle?",
+	"Why can't a bicycle stand on its own?",
+	"What do you call a bear with no teeth?",
+	"Why did the math book look so sad?",
+	"How does a penguin build its house?",
+	"Why don't eggs tell jokes?",
+	"What did the ocean say to the beach?",
+	"Why did the coffee file a police report?",
+}
+
+var jokePunchlines = []string{
+	"They don't have the guts.",
+	"Because it was outstanding in its field.",
+	"An impasta.",
+	"It's two tired.",
+	"A gummy bear.",
+	"Because it had too many problems.",
+	"Igloos it together.",
+	"They'd crack each other up.",
+	"Nothing, it just waved.",
+	"It got mugged.",
+}
+
+var waifus = []struct {
+	name     string
+	original string
+	series   string
+}{
+	{"Rem", "レム", "Re:Zero kara Hajimeru Isekai Seikatsu"},
+	{"Emilia", "エミリア", "Re:Zero kara Hajimeru Isekai Seikatsu"},
+	{"Mikasa Ackerman", "ミカサ・アッカーマン", "Shingeki no Kyojin"},
+	{"Asuna Yuuki", "結城 明日奈", "Sword Art Online"},
+	{"Holo", "ホロ", "Ookami to Koushinryou"},
+	{"Nezuko Kamado", "竈門 禰豆子", "Kimetsu no Yaiba"},
+	{"Marin Kitagawa", "喜多川 海夢", "Sono Bisque Doll wa Koi wo Suru"},
+	{"Zero Two", "ゼロツー", "Darling in the FranXX"},
+	{"Megumin", "めぐみん", "Kono Subarashii Sekai ni Shukufuku wo!"},
+	{"Mai Sakurajima", "桜島 麻衣", "Seishun Buta Yarou wa Bunny Girl Senpai no Yume wo Minai"},
+	{"Kurisu Makise", "牧瀬 紅莉栖", "Steins;Gate"},
+	{"Hinata Hyuuga", "日向 ヒナタ", "Naruto"},
+}
+
+var waifuTraits = []string{
+	"is cheerful and fiercely loyal to her friends",
+	"hides a gentle heart behind a cold expression",
+	"is a prodigy who never stops training",
+	"is known for her quick wit and sharp tongue",
+	"will do anything to protect the people she loves",
+	"is shy around strangers but brave when it counts",
+}
+
+var countries = []struct {
+	common   string
+	official string
+	cca2     string
+	cca3     string
+	capital  string
+	region   string
+}{
+	{"Japan", "Japan", "JP", "JPN", "Tokyo", "Asia"},
+	{"Canada", "Canada", "CA", "CAN", "Ottawa", "Americas"},
+	{"Brazil", "Federative Republic of Brazil", "BR", "BRA", "Brasília", "Americas"},
+	{"Germany", "Federal Republic of Germany", "DE", "DEU", "Berlin", "Europe"},
+	{"France", "French Republic", "FR", "FRA", "Paris", "Europe"},
+	{"Kenya", "Republic of Kenya", "KE", "KEN", "Nairobi", "Africa"},
+	{"Australia", "Commonwealth of Australia", "AU", "AUS", "Canberra", "Oceania"},
+	{"India", "Republic of India", "IN", "IND", "New Delhi", "Asia"},
+	{"Mexico", "United Mexican States", "MX", "MEX", "Mexico City", "Americas"},
+	{"Norway", "Kingdom of Norway", "NO", "NOR", "Oslo", "Europe"},
+	{"Egypt", "Arab Republic of Egypt", "EG", "EGY", "Cairo", "Africa"},
+	{"South Korea", "Republic of Korea", "KR", "KOR", "Seoul", "Asia"},
+	{"Argentina", "Argentine Republic", "AR", "ARG", "Buenos Aires", "Americas"},
+	{"New Zealand", "New Zealand", "NZ", "NZL", "Wellington", "Oceania"},
+	{"Portugal", "Portuguese Republic", "PT", "PRT", "Lisbon", "Europe"},
+	{"Nigeria", "Federal Republic of Nigeria", "NG", "NGA", "Abuja", "Africa"},
+	{"Chile", "Republic of Chile", "CL", "CHL", "Santiago", "Americas"},
+	{"Vietnam", "Socialist Republic of Vietnam", "VN", "VNM", "Hanoi", "Asia"},
+	{"Ireland", "Republic of Ireland", "IE", "IRL", "Dublin", "Europe"},
+	{"Morocco", "Kingdom of Morocco", "MA", "MAR", "Rabat", "Africa"},
+}
+
+var brands = []struct {
+	name string
+	clue string
+}{
+	{"GitHub", "Where developers host their code"},
+	{"Spotify", "Music streaming from Sweden"},
+	{"Discord", "Chat for communities and gamers"},
+	{"Netflix", "Binge watching made easy"},
+	{"Nike", "Just do it"},
+	{"Starbucks", "A mermaid serves your coffee"},
+	{"Twitter", "A little blue bird"},
+	{"Reddit", "The front page of the internet"},
+	{"Adidas", "Three stripes"},
+	{"Mozilla", "A fiery fox guards this browser"},
+	{"Lego", "Colourful bricks from Denmark"},
+	{"Nintendo", "Home of a famous plumber"},
+}
+
+var (
+	sentenceSubjects = []string{"The quick brown fox", "A sleepy cat", "My neighbour", "The old wizard", "Every programmer", "A tiny robot", "The captain", "Our team"}
+	sentenceVerbs    = []string{"jumps over", "writes about", "carefully inspects", "dreams of", "races past", "quietly fixes", "paints", "argues with"}
+	sentenceObjects  = []string{"the lazy dog", "a broken compiler", "the midnight train", "seven golden keys", "an endless staircase", "the purple mountains", "a stubborn bug", "the last cookie"}
+	sentenceEndings  = []string{"before sunrise.", "without a second thought.", "in complete silence.", "for no good reason.", "while humming a tune.", "again and again."}
+)
+
+// captchaCharset leaves out characters that are easy to confuse
+const captchaCharset = "abcdefghjkmnpqrstuvwxyz23456789"
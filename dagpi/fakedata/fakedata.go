@@ -0,0 +1,171 @@
+// Package fakedata generates random results for the Data calls from a seed, without any HTTP.
+// Results have the same shape and types as the ones decoded from the API, numbers are float64, arrays
+// []interface{} and objects map[string]interface{}, so game logic can be tested against thousands of them.
+package fakedata
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// DefaultImageBaseURL prefixes the image links in generated results when ImageBaseURL is not set
+const DefaultImageBaseURL = "https://example.com/dagpi"
+
+// Generator makes Data results. The same seed always gives the same sequence of results.
+// It is not safe for concurrent use.
+type Generator struct {
+	// ImageBaseURL prefixes image links, point it at a dagpitest.Server's /assets to have them load
+	ImageBaseURL string
+
+	rng *rand.Rand
+}
+
+// New creates a Generator seeded with seed
+func New(seed int64) *Generator {
+	return &Generator{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (g *Generator) image(path string) string {
+	base := g.ImageBaseURL
+	if base == "" {
+		base = DefaultImageBaseURL
+	}
+
+	return strings.TrimRight(base, "/") + "/" + path
+}
+
+func (g *Generator) id(n int) string {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	id := make([]byte, n)
+	for i := range id {
+		id[i] = chars[g.rng.Intn(len(chars))]
+	}
+
+	return string(id)
+}
+
+func list(values []string) []interface{} {
+	items := make([]interface{}, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+
+	return items
+}
+
+// WTP is like Client.WTP
+func (g *Generator) WTP() map[string]interface{} {
+	p := pokemons[g.rng.Intn(len(pokemons))]
+
+	return map[string]interface{}{
+		"Data": map[string]interface{}{
+			"abilities": list(p.abilities),
+			"ascii":     p.name,
+			"height":    p.height,
+			"id":        float64(p.id),
+			"link":      "https://pokemondb.net/pokedex/" + strings.ToLower(p.name),
+			"name":      p.name,
+			"Type":      list(p.types),
+			"weight":    p.weight,
+		},
+		"question": g.image(fmt.Sprintf("wtp/%d/question.png", p.id)),
+		"answer":   g.image(fmt.Sprintf("wtp/%d/answer.png", p.id)),
+	}
+}
+
+// Joke is like Client.Joke
+func (g *Generator) Joke() map[string]interface{} {
+	i := g.rng.Intn(len(jokeSetups))
+
+	return map[string]interface{}{
+		"id":   g.id(10),
+		"joke": jokeSetups[i] + " " + jokePunchlines[i],
+	}
+}
+
+// Waifu is like Client.RandomWaifu
+func (g *Generator) Waifu() map[string]interface{} {
+	w := waifus[g.rng.Intn(len(waifus))]
+	id := g.rng.Intn(20000) + 1
+
+	return map[string]interface{}{
+		"id":              float64(id),
+		"name":            w.name,
+		"original_name":   w.original,
+		"description":     w.name + " " + waifuTraits[g.rng.Intn(len(waifuTraits))] + ".",
+		"display_picture": g.image(fmt.Sprintf("waifu/%d.png", id)),
+		"url":             "https://mywaifulist.moe/waifu/" + strings.ToLower(strings.ReplaceAll(w.name, " ", "-")),
+		"series":          map[string]interface{}{"name": w.series},
+		"likes":           float64(g.rng.Intn(10000)),
+		"trash":           float64(g.rng.Intn(1000)),
+		"husbando":        false,
+		"nsfw":            false,
+	}
+}
+
+// Flag is like Client.Flag
+func (g *Generator) Flag() map[string]interface{} {
+	c := countries[g.rng.Intn(len(countries))]
+
+	return map[string]interface{}{
+		"Data": map[string]interface{}{
+			"name":    map[string]interface{}{"common": c.common, "official": c.official},
+			"cca2":    c.cca2,
+			"cca3":    c.cca3,
+			"capital": list([]string{c.capital}),
+			"region":  c.region,
+		},
+		"flag": g.image("flag/" + strings.ToLower(c.cca2) + ".png"),
+	}
+}
+
+// GTL is like Client.GTL
+func (g *Generator) GTL() map[string]interface{} {
+	b := brands[g.rng.Intn(len(brands))]
+	slug := strings.ToLower(b.name)
+
+	// every other letter on average is hidden
+	hint := []rune(b.name)
+	for i := range hint {
+		if g.rng.Intn(2) == 0 {
+			hint[i] = '_'
+		}
+	}
+
+	return map[string]interface{}{
+		"question": g.image("logo/" + slug + "/question.png"),
+		"answer":   g.image("logo/" + slug + "/answer.png"),
+		"brand":    b.name,
+		"clue":     b.clue,
+		"hint":     string(hint),
+		"easy":     g.rng.Intn(2) == 0,
+		"wiki_url": "https://en.wikipedia.org/wiki/" + strings.ReplaceAll(b.name, " ", "_"),
+	}
+}
+
+// Captcha is like Client.Captcha
+func (g *Generator) Captcha() map[string]interface{} {
+	answer := make([]byte, 6)
+	for i := range answer {
+		answer[i] = captchaCharset[g.rng.Intn(len(captchaCharset))]
+	}
+
+	return map[string]interface{}{
+		"image":  g.image("captcha/" + string(answer) + ".png"),
+		"answer": string(answer),
+	}
+}
+
+// Typeracer is like Client.Typeracer
+func (g *Generator) Typeracer() map[string]interface{} {
+	pick := func(words []string) string {
+		return words[g.rng.Intn(len(words))]
+	}
+	sentence := strings.Join([]string{pick(sentenceSubjects), pick(sentenceVerbs), pick(sentenceObjects), pick(sentenceEndings)}, " ")
+
+	return map[string]interface{}{
+		"image":    g.image(fmt.Sprintf("typeracer/%s.png", g.id(8))),
+		"sentence": sentence,
+	}
+}
@@ -0,0 +1,133 @@
+package fakedata_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/fakedata"
+)
+
+// generators pairs every generated result with the client call it stands in for
+func generators(g *fakedata.Generator) map[string]func() map[string]interface{} {
+	return map[string]func() map[string]interface{}{
+		"WTP":         g.WTP,
+		"Joke":        g.Joke,
+		"RandomWaifu": g.Waifu,
+		"Flag":        g.Flag,
+		"GTL":         g.GTL,
+		"Captcha":     g.Captcha,
+		"Typeracer":   g.Typeracer,
+	}
+}
+
+// sameShape reports the first key of want missing from got or holding a different type
+func sameShape(prefix string, got map[string]interface{}, want map[string]interface{}) string {
+	for key, wantValue := range want {
+		gotValue, ok := got[key]
+		if !ok {
+			return prefix + key + " is missing"
+		}
+		if reflect.TypeOf(gotValue) != reflect.TypeOf(wantValue) {
+			return prefix + key + " is " + reflect.TypeOf(gotValue).String() + ", want " + reflect.TypeOf(wantValue).String()
+		}
+		if nested, ok := wantValue.(map[string]interface{}); ok {
+			if problem := sameShape(prefix+key+".", gotValue.(map[string]interface{}), nested); problem != "" {
+				return problem
+			}
+		}
+	}
+
+	return ""
+}
+
+func TestSameSeedSameResults(t *testing.T) {
+	first, second := fakedata.New(42), fakedata.New(42)
+	for i := 0; i < 20; i++ {
+		for name, generate := range generators(first) {
+			if got, want := generate(), generators(second)[name](); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s %d = %v with the same seed, want %v", name, i, got, want)
+			}
+		}
+	}
+
+	jokes := map[string]bool{}
+	other := fakedata.New(43)
+	for i := 0; i < 20; i++ {
+		jokes[other.Joke()["id"].(string)] = true
+	}
+	if len(jokes) < 20 {
+		t.Errorf("20 jokes had %d different ids, want them all different", len(jokes))
+	}
+}
+
+func TestMatchesTheAPI(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	client := server.Client()
+
+	calls := map[string]func() (interface{}, error){
+		"WTP":         client.WTP,
+		"Joke":        client.Joke,
+		"RandomWaifu": client.RandomWaifu,
+		"Flag":        client.Flag,
+		"GTL":         client.GTL,
+		"Captcha":     client.Captcha,
+		"Typeracer":   client.Typeracer,
+	}
+
+	for name, generate := range generators(fakedata.New(1)) {
+		remote, err := calls[name]()
+		if err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+
+		generated := generate()
+		if problem := sameShape("", generated, remote.(map[string]interface{})); problem != "" {
+			t.Errorf("generated %s: %s", name, problem)
+		}
+
+		// decoding generated results as json gives them back unchanged, like the client's results
+		data, _ := json.Marshal(generated)
+		var decoded map[string]interface{}
+		if err = json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, generated) {
+			t.Errorf("generated %s changes when decoded as json", name)
+		}
+	}
+}
+
+func TestImagesLoadFromTheFakeServer(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	g := fakedata.New(1)
+	if question := g.WTP()["question"].(string); !strings.HasPrefix(question, fakedata.DefaultImageBaseURL) {
+		t.Errorf("question = %s, want it under %s", question, fakedata.DefaultImageBaseURL)
+	}
+
+	g.ImageBaseURL = server.URL + "/assets/"
+	for _, link := range []string{g.WTP()["answer"].(string), g.Flag()["flag"].(string), g.Captcha()["image"].(string)} {
+		resp, err := http.Get(link)
+		if err != nil {
+			t.Fatalf("GET %s: %v", link, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+			t.Errorf("%s answered %d %s, want a png", link, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+}
+
+func TestCaptchaAnswerMatchesImage(t *testing.T) {
+	g := fakedata.New(5)
+	for i := 0; i < 10; i++ {
+		captcha := g.Captcha()
+		answer := captcha["answer"].(string)
+		if len(answer) != 6 || !strings.Contains(captcha["image"].(string), answer) {
+			t.Errorf("captcha %v, want a 6 character answer named in the image link", captcha)
+		}
+	}
+}
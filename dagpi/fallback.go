@@ -0,0 +1,247 @@
+package dagpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend is what produced an Image
+type Backend string
+
+const (
+	BackendRemote Backend = "remote"
+	BackendLocal  Backend = "local"
+	BackendCache  Backend = "cache"
+)
+
+// FallbackReason is why an image was rendered locally instead of by the API
+type FallbackReason string
+
+const (
+	FallbackRemoteError   FallbackReason = "remote error"
+	FallbackCircuitOpen   FallbackReason = "circuit open"
+	FallbackRateLimited   FallbackReason = "rate limited"
+	FallbackLatencyBudget FallbackReason = "latency budget exceeded"
+)
+
+// Image is the result of an image call along with where it came from
+type Image struct {
+	Data    []byte
+	Backend Backend
+	// FallbackReason is set when Backend is BackendLocal
+	FallbackReason FallbackReason
+}
+
+// Renderer renders image routes without the API. local.Renderer implements it.
+type Renderer interface {
+	// Supports reports whether the route can be rendered
+	Supports(effect Effect) bool
+	// Render renders the route with the same params that would have been sent to the API.
+	// ctx is the caller's, so a cancelled call stops fetching the input image.
+	Render(ctx context.Context, effect Effect, params url.Values) ([]byte, error)
+}
+
+// FallbackPolicy decides when image calls are routed to a local Renderer instead of the API.
+// Only routes the Renderer supports are ever routed, everything else always goes to the API.
+type FallbackPolicy struct {
+	Renderer Renderer
+
+	// OnError renders locally when the API can't be reached or answers with a 5xx or 429. Other errors, like a bad
+	// token or invalid input, are returned as they are so they get fixed. Cancelled calls never fall back.
+	OnError bool
+
+	// OnRateLimit renders locally when the API answers 429 or the client's RateLimiter has no request left
+	// right now. The second needs a RateLimiter with an Allow() bool method like Limiter.
+	OnRateLimit bool
+
+	// LatencyBudget renders locally when the API hasn't answered in time, zero waits for the API
+	LatencyBudget time.Duration
+
+	// FailureThreshold opens the circuit after that many API failures in a row, calls cut off by the
+	// LatencyBudget count as failures. While it is open calls go straight to the Renderer.
+	// Zero disables the circuit breaker.
+	FailureThreshold int
+
+	// Cooldown is how long the circuit stays open before the API is tried again, defaults to 30 seconds
+	Cooldown time.Duration
+}
+
+func (p *FallbackPolicy) cooldown() time.Duration {
+	if p.Cooldown <= 0 {
+		return 30 * time.Second
+	}
+
+	return p.Cooldown
+}
+
+// breaker counts API failures in a row for the circuit breaker
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func (b *breaker) isOpen(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return now.Before(b.openUntil)
+}
+
+func (b *breaker) record(failed bool, threshold int, cooldown time.Duration, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+	}
+}
+
+// isRemoteFailure reports whether err means the API is in trouble, as opposed to a bad request
+func isRemoteFailure(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == 429
+	}
+
+	return err != nil && !isInputError(err)
+}
+
+func isRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 429
+}
+
+// route splits an image call's url into its effect and params
+func route(apiURL string) (Effect, url.Values, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return Effect(strings.TrimPrefix(strings.Trim(u.Path, "/"), "image/")), u.Query(), nil
+}
+
+type remoteResult struct {
+	image *Image
+	err   error
+}
+
+// fetch tries the API according to the policy and renders locally when it says so
+func (p *FallbackPolicy) fetch(ctx context.Context, apiURL string, c *Client) (*Image, error) {
+	effect, params, err := route(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if p.Renderer == nil || !p.Renderer.Supports(effect) {
+		return p.fetchRemote(ctx, apiURL, c, false)
+	}
+
+	if p.FailureThreshold > 0 && c.breaker.isOpen(c.clock().Now()) {
+		return p.render(ctx, effect, params, FallbackCircuitOpen, nil)
+	}
+
+	acquired := false
+	if limiter, ok := c.RateLimiter.(interface{ Allow() bool }); ok && p.OnRateLimit {
+		if !limiter.Allow() {
+			return p.render(ctx, effect, params, FallbackRateLimited, nil)
+		}
+		acquired = true
+	}
+
+	remoteCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan remoteResult, 1)
+	go func() {
+		image, err := p.fetchRemote(remoteCtx, apiURL, c, acquired)
+		done <- remoteResult{image: image, err: err}
+	}()
+
+	var budget <-chan time.Time
+	if p.LatencyBudget > 0 {
+		timer := c.clock().NewTimer(p.LatencyBudget)
+		defer timer.Stop()
+		budget = timer.C()
+	}
+
+	select {
+	case result := <-done:
+		switch {
+		case result.err == nil:
+			return result.image, nil
+		case ctx.Err() != nil:
+			return nil, result.err
+		case p.OnRateLimit && isRateLimited(result.err):
+			return p.render(ctx, effect, params, FallbackRateLimited, result.err)
+		case p.OnError && isRemoteFailure(result.err):
+			return p.render(ctx, effect, params, FallbackRemoteError, result.err)
+		}
+
+		return nil, result.err
+	case <-budget:
+		// the cancelled call doesn't record itself, so a slow API still opens the circuit
+		cancel()
+		if p.FailureThreshold > 0 {
+			c.breaker.record(true, p.FailureThreshold, p.cooldown(), c.clock().Now())
+		}
+
+		return p.render(ctx, effect, params, FallbackLatencyBudget, nil)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchRemote calls the API and keeps the circuit breaker up to date
+func (p *FallbackPolicy) fetchRemote(ctx context.Context, apiURL string, c *Client, acquired bool) (*Image, error) {
+	image, err := fetchRemote(ctx, apiURL, c, acquired)
+	if p.FailureThreshold > 0 && ctx.Err() == nil {
+		c.breaker.record(isRemoteFailure(err), p.FailureThreshold, p.cooldown(), c.clock().Now())
+	}
+
+	return image, err
+}
+
+// render uses the Renderer, if it fails too the API error is the one returned. A cancelled call isn't rendered.
+func (p *FallbackPolicy) render(ctx context.Context, effect Effect, params url.Values, reason FallbackReason, remoteErr error) (*Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	buffer, err := p.Renderer.Render(ctx, effect, params)
+	if err != nil {
+		if remoteErr != nil {
+			return nil, fmt.Errorf("%w (local fallback failed: %v)", remoteErr, err)
+		}
+
+		return nil, err
+	}
+
+	return &Image{Data: buffer, Backend: BackendLocal, FallbackReason: reason}, nil
+}
+
+func isInputError(err error) bool {
+	var inputErr *InputError
+	return errors.As(err, &inputErr)
+}
+
+// ApplyImage is Apply returning the image along with the backend that produced it
+func (c *Client) ApplyImage(effect Effect, url string) (*Image, error) {
+	return c.ApplyImageContext(context.Background(), effect, url)
+}
+
+// ApplyImageContext is ApplyImage with a context that can cancel the request
+func (c *Client) ApplyImageContext(ctx context.Context, effect Effect, url string) (*Image, error) {
+	return getImage(ctx, c.baseURL()+"/image/"+string(effect)+"/?url="+url, c)
+}
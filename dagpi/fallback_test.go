@@ -0,0 +1,245 @@
+package dagpi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// stubRenderer renders pixel and sepia as a fixed buffer and counts its calls
+type stubRenderer struct {
+	mu    sync.Mutex
+	calls int
+	ctx   context.Context
+}
+
+func (r *stubRenderer) Supports(effect dagpi.Effect) bool {
+	return effect == dagpi.EffectPixelate || effect == dagpi.EffectSepia
+}
+
+func (r *stubRenderer) Render(ctx context.Context, effect dagpi.Effect, params url.Values) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	r.ctx = ctx
+	return []byte("local " + string(effect)), nil
+}
+
+func (r *stubRenderer) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls
+}
+
+func fallbackClient(server *dagpitest.Server, policy dagpi.FallbackPolicy) (*dagpi.Client, *stubRenderer) {
+	renderer := &stubRenderer{}
+	policy.Renderer = renderer
+
+	client := server.Client()
+	client.Fallback = &policy
+
+	return client, renderer
+}
+
+func TestFallbackOnError(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	client, renderer := fallbackClient(server, dagpi.FallbackPolicy{OnError: true})
+	img, err := client.ApplyImage(dagpi.EffectPixelate, input)
+	if err != nil || img.Backend != dagpi.BackendRemote {
+		t.Fatalf("ApplyImage() = %+v, %v, want the API's image", img, err)
+	}
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		server.Fail("image/pixel", dagpitest.Fault{Status: status, Message: "down", Times: 1})
+		img, err = client.ApplyImage(dagpi.EffectPixelate, input)
+		if err != nil {
+			t.Fatalf("ApplyImage() with a %d = %v, want the local image", status, err)
+		}
+		if img.Backend != dagpi.BackendLocal || img.FallbackReason != dagpi.FallbackRemoteError {
+			t.Errorf("ApplyImage() with a %d came from %s (%s), want local (remote error)", status, img.Backend, img.FallbackReason)
+		}
+	}
+	if renderer.Calls() != 3 {
+		t.Errorf("the renderer was called %d times, want 3", renderer.Calls())
+	}
+}
+
+func TestFallbackKeepsClientErrors(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	client, renderer := fallbackClient(server, dagpi.FallbackPolicy{OnError: true, OnRateLimit: true})
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		server.Fail("image/pixel", dagpitest.Fault{Status: status, Message: "your fault", Times: 1})
+		_, err := client.ApplyImage(dagpi.EffectPixelate, input)
+
+		var apiErr *dagpi.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("ApplyImage() with a %d = %v, want the API error", status, err)
+		}
+	}
+
+	client.Auth = "wrong token"
+	if _, err := client.ApplyImage(dagpi.EffectPixelate, input); err == nil {
+		t.Error("ApplyImage() with a bad token = nil, want the API error")
+	}
+	if renderer.Calls() != 0 {
+		t.Errorf("the renderer was called %d times for client errors, want 0", renderer.Calls())
+	}
+}
+
+func TestFallbackUnsupportedEffect(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.Fail("image/wanted", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "down"})
+
+	client, renderer := fallbackClient(server, dagpi.FallbackPolicy{OnError: true})
+	if _, err := client.ApplyImage(dagpi.EffectWanted, server.URL+"/assets/input.png"); err == nil {
+		t.Error("ApplyImage() of an effect the renderer can't do = nil, want the API error")
+	}
+	if renderer.Calls() != 0 {
+		t.Error("the renderer was asked for an effect it doesn't support")
+	}
+}
+
+func TestFallbackOnRateLimit(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	client, _ := fallbackClient(server, dagpi.FallbackPolicy{OnRateLimit: true})
+	server.RateLimit(1)
+	img, err := client.ApplyImage(dagpi.EffectPixelate, input)
+	if err != nil || img.FallbackReason != dagpi.FallbackRateLimited {
+		t.Fatalf("ApplyImage() answered with 429 = %+v, %v, want a rate limited fallback", img, err)
+	}
+
+	// an empty limiter falls back without asking the API
+	clock := dagpitest.NewClock(time.Time{})
+	limiter := dagpi.NewLimiter(1, time.Minute)
+	limiter.Clock = clock
+	limiter.Allow()
+	client.RateLimiter = limiter
+
+	before := server.Requests("")
+	img, err = client.ApplyImage(dagpi.EffectPixelate, input)
+	if err != nil || img.FallbackReason != dagpi.FallbackRateLimited {
+		t.Fatalf("ApplyImage() with an empty limiter = %+v, %v, want a rate limited fallback", img, err)
+	}
+	if server.Requests("") != before {
+		t.Error("the API was called with an empty limiter")
+	}
+}
+
+func TestFallbackCircuitBreaker(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	clock := dagpitest.NewClock(time.Time{})
+	client, _ := fallbackClient(server, dagpi.FallbackPolicy{OnError: true, FailureThreshold: 2, Cooldown: time.Minute})
+	client.Clock = clock
+
+	server.Fail("image/pixel", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "down", Times: 2})
+	for i := 0; i < 2; i++ {
+		if _, err := client.ApplyImage(dagpi.EffectPixelate, input); err != nil {
+			t.Fatalf("ApplyImage() = %v", err)
+		}
+	}
+
+	img, err := client.ApplyImage(dagpi.EffectPixelate, input)
+	if err != nil || img.FallbackReason != dagpi.FallbackCircuitOpen {
+		t.Fatalf("ApplyImage() after 2 failures = %+v, %v, want the circuit open", img, err)
+	}
+	if server.Requests("image/pixel") != 2 {
+		t.Errorf("the API got %d requests with the circuit open, want 2", server.Requests("image/pixel"))
+	}
+
+	clock.Advance(time.Minute)
+	img, err = client.ApplyImage(dagpi.EffectPixelate, input)
+	if err != nil || img.Backend != dagpi.BackendRemote {
+		t.Errorf("ApplyImage() after the cooldown = %+v, %v, want the API to be tried again", img, err)
+	}
+}
+
+func TestFallbackLatencyBudget(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.Clock = clock
+	server.SetLatency(time.Hour)
+	input := server.URL + "/assets/input.png"
+
+	client, _ := fallbackClient(server, dagpi.FallbackPolicy{LatencyBudget: time.Second, FailureThreshold: 1, Cooldown: time.Minute})
+	client.Clock = clock
+
+	done := make(chan *dagpi.Image, 1)
+	go func() {
+		img, err := client.ApplyImage(dagpi.EffectPixelate, input)
+		if err != nil {
+			t.Errorf("ApplyImage() = %v", err)
+		}
+		done <- img
+	}()
+
+	// the server's latency and the budget
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	if img := <-done; img == nil || img.FallbackReason != dagpi.FallbackLatencyBudget {
+		t.Fatalf("ApplyImage() = %+v, want the latency budget fallback", img)
+	}
+
+	// the slow call counts as a failure, so with a threshold of 1 the circuit is open now
+	server.SetLatency(0)
+	img, err := client.ApplyImage(dagpi.EffectPixelate, input)
+	if err != nil || img.FallbackReason != dagpi.FallbackCircuitOpen {
+		t.Errorf("ApplyImage() after the budget ran out = %+v, %v, want the circuit open", img, err)
+	}
+}
+
+type callerKey struct{}
+
+func TestFallbackRendersWithCallerContext(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	client, renderer := fallbackClient(server, dagpi.FallbackPolicy{OnError: true, FailureThreshold: 1, Cooldown: time.Minute})
+	client.Clock = dagpitest.NewClock(time.Time{})
+	server.Fail("image/pixel", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "down", Times: 1})
+	if _, err := client.ApplyImage(dagpi.EffectPixelate, input); err != nil {
+		t.Fatalf("ApplyImage() = %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), callerKey{}, "caller")
+	if img, err := client.ApplyImageContext(ctx, dagpi.EffectPixelate, input); err != nil || img.FallbackReason != dagpi.FallbackCircuitOpen {
+		t.Fatalf("ApplyImageContext() = %+v, %v, want the circuit open", img, err)
+	}
+	if renderer.ctx.Value(callerKey{}) != "caller" {
+		t.Error("the Renderer didn't get the caller's context")
+	}
+
+	// with the circuit open nothing waits on the API, a cancelled call still isn't rendered
+	calls := renderer.Calls()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ApplyImageContext(cancelled, dagpi.EffectPixelate, input); !errors.Is(err, context.Canceled) {
+		t.Errorf("ApplyImageContext() with a cancelled context = %v, want context.Canceled", err)
+	}
+	if renderer.Calls() != calls {
+		t.Error("a cancelled call was rendered")
+	}
+}
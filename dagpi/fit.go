@@ -0,0 +1,225 @@
+package dagpi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// ErrCannotFit is returned by Fit when the image can't be made small enough
+var ErrCannotFit = errors.New("image can't be made to fit the byte budget")
+
+// FitReport describes what Fit did to an image
+type FitReport struct {
+	OriginalBytes int
+	Bytes         int
+
+	// Format of the result, "png", "jpeg" or "gif"
+	Format string
+
+	OriginalWidth  int
+	OriginalHeight int
+	Width          int
+	Height         int
+
+	// Frames and Colors are only set for gifs
+	OriginalFrames int
+	Frames         int
+	Colors         int
+
+	// JPEGQuality is set when the image was re-encoded as a jpeg
+	JPEGQuality int
+
+	// Changes lists every change made, in order
+	Changes []string
+}
+
+// Changed reports whether Fit had to change the image at all
+func (r *FitReport) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// smallest side Fit will scale down to before giving up
+const minFitSide = 32
+
+// Fit shrinks the image until it is at most maxBytes, for chat platforms with upload limits.
+// Gifs first lose colors, then every other frame and then size. Other images are re-encoded as jpegs,
+// unless they have transparency, and then scaled down. Images that already fit are returned unchanged.
+func (img *Image) Fit(maxBytes int) (*Image, *FitReport, error) {
+	report := &FitReport{OriginalBytes: len(img.Data), Bytes: len(img.Data)}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Format = format
+	report.OriginalWidth, report.Width = config.Width, config.Width
+	report.OriginalHeight, report.Height = config.Height, config.Height
+
+	if len(img.Data) <= maxBytes {
+		return img, report, nil
+	}
+
+	var data []byte
+	if format == "gif" {
+		data, err = fitGIF(img.Data, maxBytes, report)
+	} else {
+		data, err = fitStill(img.Data, maxBytes, report)
+	}
+	if err != nil {
+		return nil, report, err
+	}
+	report.Bytes = len(data)
+
+	return img.withData(data), report, nil
+}
+
+func fitStill(data []byte, maxBytes int, report *FitReport) ([]byte, error) {
+	decoded, _, err := imgutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	src := imgutil.NRGBA(decoded)
+
+	opaque := src.Opaque()
+	encode := func(img *image.NRGBA, quality int) ([]byte, error) {
+		if opaque {
+			var buf bytes.Buffer
+			err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+			return buf.Bytes(), err
+		}
+
+		return imgutil.EncodePNG(img)
+	}
+
+	if opaque {
+		report.Format = "jpeg"
+		for _, quality := range []int{90, 80, 70, 60} {
+			out, err := encode(src, quality)
+			if err != nil {
+				return nil, err
+			}
+			report.JPEGQuality = quality
+			if len(out) <= maxBytes {
+				report.Changes = append(report.Changes, fmt.Sprintf("re-encoded as jpeg at quality %d", quality))
+				return out, nil
+			}
+		}
+		report.Changes = append(report.Changes, fmt.Sprintf("re-encoded as jpeg at quality %d", report.JPEGQuality))
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	for {
+		w, h = w*3/4, h*3/4
+		if w < minFitSide || h < minFitSide {
+			return nil, ErrCannotFit
+		}
+
+		out, err := encode(imgutil.Resize(src, w, h), report.JPEGQuality)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) <= maxBytes {
+			report.Width, report.Height = w, h
+			report.Changes = append(report.Changes, fmt.Sprintf("scaled down to %dx%d", w, h))
+			return out, nil
+		}
+	}
+}
+
+func fitGIF(data []byte, maxBytes int, report *FitReport) ([]byte, error) {
+	anim, err := imgutil.DecodeGIF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := imgutil.Frames(anim)
+	delays := append([]int(nil), anim.Delay...)
+	report.OriginalFrames, report.Frames = len(frames), len(frames)
+	report.Colors = 256
+
+	encode := func() ([]byte, error) {
+		palette := imgutil.Quantize(frames, report.Colors)
+		out := &gif.GIF{LoopCount: anim.LoopCount}
+		for i, frame := range frames {
+			out.Image = append(out.Image, imgutil.Paletted(frame, palette, false))
+			out.Delay = append(out.Delay, delays[i])
+			out.Disposal = append(out.Disposal, gif.DisposalNone)
+		}
+
+		var buf bytes.Buffer
+		err := gif.EncodeAll(&buf, out)
+		return buf.Bytes(), err
+	}
+
+	// fewer colors compress better and are the least noticeable change
+	for _, colors := range []int{128, 64, 32} {
+		report.Colors = colors
+		out, err := encode()
+		if err != nil {
+			return nil, err
+		}
+		if len(out) <= maxBytes {
+			report.Changes = append(report.Changes, fmt.Sprintf("reduced to %d colors", colors))
+			return out, nil
+		}
+	}
+	report.Changes = append(report.Changes, fmt.Sprintf("reduced to %d colors", report.Colors))
+
+	// drop every other frame, adding its delay to the one kept so the animation runs at the same speed
+	for len(frames)/2 >= 4 {
+		var keptFrames []*image.NRGBA
+		var keptDelays []int
+		for i := 0; i < len(frames); i += 2 {
+			delay := delays[i]
+			if i+1 < len(frames) {
+				delay += delays[i+1]
+			}
+			keptFrames = append(keptFrames, frames[i])
+			keptDelays = append(keptDelays, delay)
+		}
+		frames, delays = keptFrames, keptDelays
+		report.Frames = len(frames)
+
+		out, err := encode()
+		if err != nil {
+			return nil, err
+		}
+		if len(out) <= maxBytes {
+			report.Changes = append(report.Changes, fmt.Sprintf("dropped to %d frames", len(frames)))
+			return out, nil
+		}
+	}
+	if report.Frames != report.OriginalFrames {
+		report.Changes = append(report.Changes, fmt.Sprintf("dropped to %d frames", len(frames)))
+	}
+
+	original := frames
+	w, h := report.OriginalWidth, report.OriginalHeight
+	for {
+		w, h = w*3/4, h*3/4
+		if w < minFitSide || h < minFitSide {
+			return nil, ErrCannotFit
+		}
+
+		frames = make([]*image.NRGBA, len(original))
+		for i, frame := range original {
+			frames[i] = imgutil.Resize(frame, w, h)
+		}
+
+		out, err := encode()
+		if err != nil {
+			return nil, err
+		}
+		if len(out) <= maxBytes {
+			report.Width, report.Height = w, h
+			report.Changes = append(report.Changes, fmt.Sprintf("scaled down to %dx%d", w, h))
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package dagpi_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// noisePNG is a png that barely compresses, alpha sets the transparency of every pixel
+func noisePNG(t *testing.T, size int, alpha uint8) []byte {
+	t.Helper()
+
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	rng.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = alpha
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encoding png: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// noiseGIF is a gif of random frames over a full palette
+func noiseGIF(t *testing.T, size int, frames int) []byte {
+	t.Helper()
+
+	rng := rand.New(rand.NewSource(1))
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.RGBA{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: 255}
+	}
+
+	anim := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+		rng.Read(frame.Pix)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 5)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("encoding gif: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFitUnchanged(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	img, err := server.Client().ApplyImage(dagpi.EffectPixelate, server.URL+"/assets/input.png")
+	if err != nil {
+		t.Fatalf("ApplyImage() = %v", err)
+	}
+
+	fitted, report, err := img.Fit(len(img.Data))
+	if err != nil {
+		t.Fatalf("Fit() = %v", err)
+	}
+	if report.Changed() || !bytes.Equal(fitted.Data, img.Data) {
+		t.Errorf("Fit() changed an image that already fit: %v", report.Changes)
+	}
+	if fitted.Backend != dagpi.BackendRemote {
+		t.Errorf("Backend = %s, want remote", fitted.Backend)
+	}
+}
+
+func TestFitOpaque(t *testing.T) {
+	img := &dagpi.Image{Data: noisePNG(t, 200, 255)}
+	budget := len(img.Data) / 4
+
+	fitted, report, err := img.Fit(budget)
+	if err != nil {
+		t.Fatalf("Fit() = %v", err)
+	}
+	if len(fitted.Data) > budget || report.Bytes != len(fitted.Data) {
+		t.Errorf("Fit() gave %d bytes (report says %d), want at most %d", len(fitted.Data), report.Bytes, budget)
+	}
+	if report.Format != "jpeg" || report.JPEGQuality == 0 {
+		t.Errorf("Format = %s at quality %d, want a jpeg", report.Format, report.JPEGQuality)
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(fitted.Data)); err != nil || format != "jpeg" {
+		t.Errorf("the result is %s (%v), want a jpeg", format, err)
+	}
+}
+
+func TestFitTransparent(t *testing.T) {
+	img := &dagpi.Image{Data: noisePNG(t, 200, 128)}
+	budget := len(img.Data) / 3
+
+	fitted, report, err := img.Fit(budget)
+	if err != nil {
+		t.Fatalf("Fit() = %v", err)
+	}
+	if len(fitted.Data) > budget {
+		t.Errorf("Fit() gave %d bytes, want at most %d", len(fitted.Data), budget)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(fitted.Data))
+	if err != nil || format != "png" {
+		t.Fatalf("the result is %s (%v), want a png to keep the transparency", format, err)
+	}
+	if config.Width >= 200 || config.Width != report.Width {
+		t.Errorf("the result is %d wide and the report says %d, want it scaled down", config.Width, report.Width)
+	}
+}
+
+func TestFitGIF(t *testing.T) {
+	img := &dagpi.Image{Data: noiseGIF(t, 64, 16)}
+	budget := len(img.Data) / 3
+
+	fitted, report, err := img.Fit(budget)
+	if err != nil {
+		t.Fatalf("Fit() = %v", err)
+	}
+	if len(fitted.Data) > budget {
+		t.Errorf("Fit() gave %d bytes, want at most %d", len(fitted.Data), budget)
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(fitted.Data))
+	if err != nil {
+		t.Fatalf("the result isn't a gif: %v", err)
+	}
+	if len(anim.Image) != report.Frames || report.OriginalFrames != 16 {
+		t.Errorf("the result has %d frames, the report says %d of 16", len(anim.Image), report.Frames)
+	}
+
+	// dropped frames hand their delay to the frames kept, so the animation runs as long as before
+	total := 0
+	for _, delay := range anim.Delay {
+		total += delay
+	}
+	if total != 16*5 {
+		t.Errorf("the animation runs for %d, want %d", total, 16*5)
+	}
+}
+
+func TestFitCannotFit(t *testing.T) {
+	img := &dagpi.Image{Data: noisePNG(t, 100, 128)}
+	if _, _, err := img.Fit(10); !errors.Is(err, dagpi.ErrCannotFit) {
+		t.Errorf("Fit(10) = %v, want ErrCannotFit", err)
+	}
+}
+
+func TestFitRefusesHugeGIFs(t *testing.T) {
+	// tiny frames on a big canvas would each be drawn on the full canvas before fitting
+	palette := color.Palette{color.RGBA{A: 255}, color.RGBA{R: 255, A: 255}}
+	anim := &gif.GIF{Config: image.Config{Width: 1000, Height: 1000, ColorModel: palette}}
+	for i := 0; i < 100; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(i, i, i+1, i+1), palette))
+		anim.Delay = append(anim.Delay, 1)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("encoding gif: %v", err)
+	}
+
+	img := &dagpi.Image{Data: buf.Bytes()}
+	if _, _, err := img.Fit(len(img.Data) / 2); !errors.Is(err, imgutil.ErrTooLarge) {
+		t.Errorf("Fit() = %v, want ErrTooLarge", err)
+	}
+}
@@ -0,0 +1,266 @@
+package dagpi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"math"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// ErrNotGIF is returned by the gif helpers for images that aren't gifs
+var ErrNotGIF = errors.New("image is not a gif")
+
+// SpriteSheet is every frame of a gif laid out in a grid
+type SpriteSheet struct {
+	// Image is the sheet as a png
+	Image []byte `json:"-"`
+
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	FrameWidth  int           `json:"frameWidth"`
+	FrameHeight int           `json:"frameHeight"`
+	Columns     int           `json:"columns"`
+	Rows        int           `json:"rows"`
+	LoopCount   int           `json:"loopCount"`
+	Frames      []SpriteFrame `json:"frames"`
+}
+
+// SpriteFrame is where a frame sits on a SpriteSheet and how long it is shown for, in 100ths of a second
+type SpriteFrame struct {
+	Index int `json:"index"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Delay int `json:"delay"`
+}
+
+// Metadata returns the sheet's layout and frame timings as json
+func (s *SpriteSheet) Metadata() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// withData returns a new Image with the same origin as img
+func (img *Image) withData(data []byte) *Image {
+	return &Image{Data: data, Backend: img.Backend, FallbackReason: img.FallbackReason}
+}
+
+func (img *Image) isGIF() bool {
+	_, format, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	return err == nil && format == "gif"
+}
+
+func (img *Image) decodeGIF() (*gif.GIF, error) {
+	if !img.isGIF() {
+		return nil, ErrNotGIF
+	}
+
+	return imgutil.DecodeGIF(img.Data)
+}
+
+func encodeGIF(g *gif.GIF) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gif.EncodeAll(&buf, g)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FrameCount returns the number of frames in the image, 1 for images that aren't gifs
+func (img *Image) FrameCount() (int, error) {
+	if !img.isGIF() {
+		if _, _, err := image.DecodeConfig(bytes.NewReader(img.Data)); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	g, err := img.decodeGIF()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(g.Image), nil
+}
+
+// FirstFrame returns the first frame of a gif as a png. Images that aren't gifs are converted to a png.
+func (img *Image) FirstFrame() ([]byte, error) {
+	return img.Frame(0)
+}
+
+// Frame returns frame index of a gif as a png, drawn the way a viewer would show it. Only the frames up to index
+// are drawn. Gifs whose frames would take too much memory to draw on the full canvas are refused, as they are
+// by the other gif helpers.
+func (img *Image) Frame(index int) ([]byte, error) {
+	if !img.isGIF() {
+		if index != 0 {
+			return nil, fmt.Errorf("frame %d is out of range, the image has 1 frame", index)
+		}
+		decoded, _, err := imgutil.Decode(img.Data)
+		if err != nil {
+			return nil, err
+		}
+		return imgutil.EncodePNG(decoded)
+	}
+
+	g, err := img.decodeGIF()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(g.Image) {
+		return nil, fmt.Errorf("frame %d is out of range, the gif has %d frames", index, len(g.Image))
+	}
+
+	return imgutil.EncodePNG(imgutil.Frame(g, index))
+}
+
+// WithDelay returns a copy of the gif with new frame delays in 100ths of a second.
+// A single delay is used for every frame, otherwise there must be one per frame.
+func (img *Image) WithDelay(delays ...int) (*Image, error) {
+	g, err := img.decodeGIF()
+	if err != nil {
+		return nil, err
+	}
+	if len(delays) != 1 && len(delays) != len(g.Image) {
+		return nil, fmt.Errorf("got %d delays for %d frames", len(delays), len(g.Image))
+	}
+
+	for i := range g.Delay {
+		if len(delays) == 1 {
+			g.Delay[i] = delays[0]
+		} else {
+			g.Delay[i] = delays[i]
+		}
+	}
+
+	data, err := encodeGIF(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return img.withData(data), nil
+}
+
+// WithLoopCount returns a copy of the gif with a new loop count.
+// 0 loops forever, -1 plays once and n plays n+1 times.
+func (img *Image) WithLoopCount(loopCount int) (*Image, error) {
+	g, err := img.decodeGIF()
+	if err != nil {
+		return nil, err
+	}
+	g.LoopCount = loopCount
+
+	data, err := encodeGIF(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return img.withData(data), nil
+}
+
+// Reverse returns a copy of the gif that plays backwards. Frames keep their own palettes, only frames drawn
+// over the ones before them are redrawn in full, with a palette of their own, so they look the same in any order.
+func (img *Image) Reverse() (*Image, error) {
+	g, err := img.decodeGIF()
+	if err != nil {
+		return nil, err
+	}
+
+	var full []*image.NRGBA
+	reversed := &gif.GIF{LoopCount: g.LoopCount, Config: g.Config}
+	for i := len(g.Image) - 1; i >= 0; i-- {
+		frame := g.Image[i]
+		if !standsAlone(g, i) {
+			if full == nil {
+				full = imgutil.Frames(g)
+			}
+			frame = imgutil.Paletted(full[i], imgutil.Quantize(full[i:i+1], 256), false)
+		}
+
+		reversed.Image = append(reversed.Image, frame)
+		reversed.Delay = append(reversed.Delay, g.Delay[i])
+		// every frame is complete now, so clearing it never uncovers a frame that doesn't belong under it
+		reversed.Disposal = append(reversed.Disposal, gif.DisposalBackground)
+	}
+
+	data, err := encodeGIF(reversed)
+	if err != nil {
+		return nil, err
+	}
+
+	return img.withData(data), nil
+}
+
+// standsAlone reports whether frame i covers the whole gif without a transparent pixel,
+// so it looks the same whatever was shown before it
+func standsAlone(g *gif.GIF, i int) bool {
+	frame := g.Image[i]
+	if frame.Bounds() != image.Rect(0, 0, g.Config.Width, g.Config.Height) {
+		return false
+	}
+
+	transparent := make([]bool, len(frame.Palette))
+	for j, c := range frame.Palette {
+		_, _, _, a := c.RGBA()
+		transparent[j] = a < 0xffff
+	}
+	for _, index := range frame.Pix {
+		if int(index) >= len(transparent) || transparent[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SpriteSheet lays every frame of the gif out left to right, top to bottom in a grid.
+// columns <= 0 picks a roughly square grid.
+func (img *Image) SpriteSheet(columns int) (*SpriteSheet, error) {
+	g, err := img.decodeGIF()
+	if err != nil {
+		return nil, err
+	}
+
+	frames := imgutil.Frames(g)
+	if len(frames) == 0 {
+		return nil, errors.New("gif has no frames")
+	}
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(frames)))))
+	}
+	if columns > len(frames) {
+		columns = len(frames)
+	}
+	rows := (len(frames) + columns - 1) / columns
+
+	frameWidth, frameHeight := frames[0].Bounds().Dx(), frames[0].Bounds().Dy()
+	sheet := &SpriteSheet{
+		Width:       frameWidth * columns,
+		Height:      frameHeight * rows,
+		FrameWidth:  frameWidth,
+		FrameHeight: frameHeight,
+		Columns:     columns,
+		Rows:        rows,
+		LoopCount:   g.LoopCount,
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, sheet.Width, sheet.Height))
+	for i, frame := range frames {
+		x, y := i%columns*frameWidth, i/columns*frameHeight
+		draw.Draw(canvas, image.Rect(x, y, x+frameWidth, y+frameHeight), frame, frame.Bounds().Min, draw.Src)
+		sheet.Frames = append(sheet.Frames, SpriteFrame{Index: i, X: x, Y: y, Delay: g.Delay[i]})
+	}
+
+	sheet.Image, err = imgutil.EncodePNG(canvas)
+	if err != nil {
+		return nil, err
+	}
+
+	return sheet, nil
+}
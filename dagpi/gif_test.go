@@ -0,0 +1,283 @@
+package dagpi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+func encodeTestGIF(t *testing.T, anim *gif.GIF) *dagpi.Image {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("encoding gif: %v", err)
+	}
+
+	return &dagpi.Image{Data: buf.Bytes(), Backend: dagpi.BackendRemote}
+}
+
+// localPalettes is a two frame gif where each frame has 200 colors of its own, more than one palette can hold
+func localPalettes(t *testing.T) *dagpi.Image {
+	anim := &gif.GIF{}
+	for f := 0; f < 2; f++ {
+		palette := make(color.Palette, 200)
+		for i := range palette {
+			palette[i] = color.RGBA{R: uint8(i), G: uint8(f * 100), B: uint8(255 - i), A: 255}
+		}
+		frame := image.NewPaletted(image.Rect(0, 0, 20, 10), palette)
+		for i := range frame.Pix {
+			frame.Pix[i] = uint8(i % 200)
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10*(f+1))
+	}
+
+	return encodeTestGIF(t, anim)
+}
+
+// partialFrames is a gif whose second frame only redraws a patch of the first
+func partialFrames(t *testing.T) *dagpi.Image {
+	palette := color.Palette{color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	first := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+	patch := image.NewPaletted(image.Rect(2, 2, 5, 5), palette)
+	draw.Draw(patch, patch.Bounds(), image.NewUniform(palette[1]), image.Point{}, draw.Src)
+
+	return encodeTestGIF(t, &gif.GIF{
+		Image:    []*image.Paletted{first, patch},
+		Delay:    []int{5, 5},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalNone},
+	})
+}
+
+func decodeFrame(t *testing.T, img *dagpi.Image, index int) image.Image {
+	t.Helper()
+
+	data, err := img.Frame(index)
+	if err != nil {
+		t.Fatalf("Frame(%d) = %v", index, err)
+	}
+	frame, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Frame(%d) isn't a png: %v", index, err)
+	}
+
+	return frame
+}
+
+func sameImage(a image.Image, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y++ {
+		for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x++ {
+			if color.NRGBAModel.Convert(a.At(x, y)) != color.NRGBAModel.Convert(b.At(x, y)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestReverseKeepsLocalPalettes(t *testing.T) {
+	img := localPalettes(t)
+	reversed, err := img.Reverse()
+	if err != nil {
+		t.Fatalf("Reverse() = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if !sameImage(decodeFrame(t, reversed, i), decodeFrame(t, img, 1-i)) {
+			t.Errorf("reversed frame %d lost colors of frame %d", i, 1-i)
+		}
+	}
+
+	anim, _ := gif.DecodeAll(bytes.NewReader(reversed.Data))
+	if anim.Delay[0] != 20 || anim.Delay[1] != 10 {
+		t.Errorf("delays = %v, want them reversed to [20 10]", anim.Delay)
+	}
+	if reversed.Backend != dagpi.BackendRemote {
+		t.Errorf("Backend = %s, want it kept", reversed.Backend)
+	}
+}
+
+func TestReverseRedrawsPartialFrames(t *testing.T) {
+	img := partialFrames(t)
+	reversed, err := img.Reverse()
+	if err != nil {
+		t.Fatalf("Reverse() = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if !sameImage(decodeFrame(t, reversed, i), decodeFrame(t, img, 1-i)) {
+			t.Errorf("reversed frame %d doesn't look like frame %d", i, 1-i)
+		}
+	}
+}
+
+func TestFrames(t *testing.T) {
+	img := partialFrames(t)
+	if count, err := img.FrameCount(); err != nil || count != 2 {
+		t.Errorf("FrameCount() = %d, %v, want 2", count, err)
+	}
+
+	// the patch is drawn over the first frame the way a viewer shows it
+	second := decodeFrame(t, img, 1)
+	if second.Bounds() != image.Rect(0, 0, 10, 10) {
+		t.Errorf("frame 1 is %v, want the whole 10x10 gif", second.Bounds())
+	}
+	if r, _, _, _ := second.At(0, 0).RGBA(); r != 0xffff {
+		t.Error("frame 1 lost the first frame under the patch")
+	}
+
+	if _, err := img.Frame(2); err == nil {
+		t.Error("Frame(2) of a 2 frame gif = nil, want an error")
+	}
+
+	still := &dagpi.Image{Data: dagpitest.PlaceholderPNG("still")}
+	if count, err := still.FrameCount(); err != nil || count != 1 {
+		t.Errorf("FrameCount() of a png = %d, %v, want 1", count, err)
+	}
+	if _, err := still.FirstFrame(); err != nil {
+		t.Errorf("FirstFrame() of a png = %v", err)
+	}
+	if _, err := still.Reverse(); !errors.Is(err, dagpi.ErrNotGIF) {
+		t.Errorf("Reverse() of a png = %v, want ErrNotGIF", err)
+	}
+}
+
+func TestFramesDisposal(t *testing.T) {
+	red, blue, green := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}, color.RGBA{G: 255, A: 255}
+	palette := color.Palette{color.RGBA{}, red, blue, green}
+	patch := func(r image.Rectangle, c color.Color) *image.Paletted {
+		frame := image.NewPaletted(r, palette)
+		draw.Draw(frame, r, image.NewUniform(c), image.Point{}, draw.Src)
+		return frame
+	}
+	img := encodeTestGIF(t, &gif.GIF{
+		Image: []*image.Paletted{
+			patch(image.Rect(0, 0, 10, 10), red),
+			patch(image.Rect(0, 0, 5, 5), blue),
+			patch(image.Rect(5, 5, 10, 10), green),
+			patch(image.Rect(0, 5, 5, 10), blue),
+		},
+		Delay:    []int{5, 5, 5, 5},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalPrevious, gif.DisposalBackground, gif.DisposalNone},
+	})
+
+	for i, want := range []map[image.Point]color.Color{
+		{{1, 1}: red, {6, 6}: red},
+		{{1, 1}: blue, {6, 6}: red},
+		{{1, 1}: red, {6, 6}: green},
+		{{1, 1}: red, {6, 6}: color.RGBA{}, {1, 6}: blue},
+	} {
+		frame := decodeFrame(t, img, i)
+		for p, c := range want {
+			if color.NRGBAModel.Convert(frame.At(p.X, p.Y)) != color.NRGBAModel.Convert(c) {
+				t.Errorf("frame %d at %v = %v, want %v", i, p, frame.At(p.X, p.Y), c)
+			}
+		}
+	}
+}
+
+func TestFramesRefuseHugeCanvases(t *testing.T) {
+	// a hundred 1x1 frames on a 1000x1000 canvas are a tiny file that takes 400MB to draw in full
+	palette := color.Palette{color.RGBA{A: 255}, color.RGBA{R: 255, A: 255}}
+	anim := &gif.GIF{Config: image.Config{Width: 1000, Height: 1000, ColorModel: palette}}
+	for i := 0; i < 100; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(i, i, i+1, i+1), palette))
+		anim.Delay = append(anim.Delay, 1)
+	}
+	img := encodeTestGIF(t, anim)
+
+	if _, err := img.FirstFrame(); !errors.Is(err, imgutil.ErrTooLarge) {
+		t.Errorf("FirstFrame() = %v, want ErrTooLarge", err)
+	}
+	if _, err := img.Reverse(); !errors.Is(err, imgutil.ErrTooLarge) {
+		t.Errorf("Reverse() = %v, want ErrTooLarge", err)
+	}
+	if _, err := img.SpriteSheet(0); !errors.Is(err, imgutil.ErrTooLarge) {
+		t.Errorf("SpriteSheet() = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestWithDelayAndLoopCount(t *testing.T) {
+	img := partialFrames(t)
+
+	slower, err := img.WithDelay(20)
+	if err != nil {
+		t.Fatalf("WithDelay(20) = %v", err)
+	}
+	looped, err := slower.WithLoopCount(3)
+	if err != nil {
+		t.Fatalf("WithLoopCount(3) = %v", err)
+	}
+	anim, _ := gif.DecodeAll(bytes.NewReader(looped.Data))
+	if anim.Delay[0] != 20 || anim.Delay[1] != 20 || anim.LoopCount != 3 {
+		t.Errorf("delays %v and loop count %d, want [20 20] and 3", anim.Delay, anim.LoopCount)
+	}
+
+	each, err := img.WithDelay(1, 2)
+	if err != nil {
+		t.Fatalf("WithDelay(1, 2) = %v", err)
+	}
+	anim, _ = gif.DecodeAll(bytes.NewReader(each.Data))
+	if anim.Delay[0] != 1 || anim.Delay[1] != 2 {
+		t.Errorf("delays = %v, want [1 2]", anim.Delay)
+	}
+
+	if _, err = img.WithDelay(1, 2, 3); err == nil {
+		t.Error("WithDelay() with 3 delays for 2 frames = nil, want an error")
+	}
+}
+
+func TestSpriteSheet(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	img, err := server.Client().ApplyImage(dagpi.EffectTriggered, server.URL+"/assets/input.png")
+	if err != nil {
+		t.Fatalf("ApplyImage() = %v", err)
+	}
+	frames, err := img.FrameCount()
+	if err != nil {
+		t.Fatalf("FrameCount() = %v", err)
+	}
+
+	sheet, err := img.SpriteSheet(4)
+	if err != nil {
+		t.Fatalf("SpriteSheet() = %v", err)
+	}
+	if len(sheet.Frames) != frames || sheet.Columns != 4 || sheet.Rows != (frames+3)/4 {
+		t.Errorf("sheet has %d frames in %dx%d, want %d in 4 columns", len(sheet.Frames), sheet.Columns, sheet.Rows, frames)
+	}
+	last := sheet.Frames[len(sheet.Frames)-1]
+	if last.X != (frames-1)%4*sheet.FrameWidth || last.Y != (frames-1)/4*sheet.FrameHeight {
+		t.Errorf("the last frame is at %d,%d, want it in its grid cell", last.X, last.Y)
+	}
+
+	config, err := png.DecodeConfig(bytes.NewReader(sheet.Image))
+	if err != nil || config.Width != sheet.Width || config.Height != sheet.Height {
+		t.Errorf("the sheet png is %dx%d (%v), want %dx%d", config.Width, config.Height, err, sheet.Width, sheet.Height)
+	}
+
+	metadata, err := sheet.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() = %v", err)
+	}
+	var decoded dagpi.SpriteSheet
+	if err = json.Unmarshal(metadata, &decoded); err != nil || len(decoded.Frames) != frames {
+		t.Errorf("Metadata() doesn't round trip: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package dagpi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HARRedacted replaces the value of redacted headers and params in a HAR
+const HARRedacted = "REDACTED"
+
+// HAR captures the client's traffic with the API as a HAR 1.2 file that can be attached to bug reports.
+// The Authorization header is always redacted. The zero value is ready to use.
+type HAR struct {
+	// RedactHeaders are request and response headers whose values are left out
+	RedactHeaders []string
+
+	// RedactParams are query params whose values are left out, like "url" to hide user links
+	RedactParams []string
+
+	// MaxImageBytes cuts image bodies down to this many bytes, zero keeps them whole and -1 leaves them out
+	MaxImageBytes int
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Cookies     []harPair `json:"cookies"`
+	Headers     []harPair `json:"headers"`
+	QueryString []harPair `json:"queryString"`
+	HeadersSize int       `json:"headersSize"`
+	BodySize    int       `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Cookies     []harPair  `json:"cookies"`
+	Headers     []harPair  `json:"headers"`
+	Content     harContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int        `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func (h *HAR) redactedHeader(name string) bool {
+	if strings.EqualFold(name, "Authorization") {
+		return true
+	}
+	for _, header := range h.RedactHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *HAR) redactedParam(name string) bool {
+	for _, param := range h.RedactParams {
+		if name == param {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (h *HAR) headers(header http.Header) []harPair {
+	pairs := []harPair{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			if h.redactedHeader(name) {
+				value = HARRedacted
+			}
+			pairs = append(pairs, harPair{Name: name, Value: value})
+		}
+	}
+
+	return pairs
+}
+
+// redactURL redacts params in the url and returns it along with its query string
+func (h *HAR) redactURL(u *url.URL) (string, []harPair) {
+	query := u.Query()
+	pairs := []harPair{}
+	redacted := false
+	for _, name := range sortedKeys(query) {
+		values := query[name]
+		for i, value := range values {
+			if h.redactedParam(name) {
+				values[i] = HARRedacted
+				value = HARRedacted
+				redacted = true
+			}
+			pairs = append(pairs, harPair{Name: name, Value: value})
+		}
+	}
+	if !redacted {
+		return u.String(), pairs
+	}
+
+	// re-encoding changes how the other params are escaped, so only do it when something was redacted
+	clean := *u
+	clean.RawQuery = query.Encode()
+
+	return clean.String(), pairs
+}
+
+func (h *HAR) content(contentType string, body []byte) harContent {
+	content := harContent{Size: len(body), MimeType: contentType}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if strings.HasPrefix(mediaType, "image/") {
+		switch {
+		case h.MaxImageBytes < 0:
+			content.Comment = "image body left out"
+			return content
+		case h.MaxImageBytes > 0 && len(body) > h.MaxImageBytes:
+			content.Comment = fmt.Sprintf("image body cut from %d to %d bytes", len(body), h.MaxImageBytes)
+			body = body[:h.MaxImageBytes]
+		}
+	}
+
+	if utf8.Valid(body) && !strings.HasPrefix(mediaType, "image/") {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+
+	return content
+}
+
+// errorComment describes a failed request. A *url.Error names the url it failed on, so that url is swapped
+// for the redacted one rather than leaking the params the HAR leaves out.
+func errorComment(err error, requestURL string) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Sprintf("%s %q: %v", urlErr.Op, requestURL, urlErr.Err)
+	}
+
+	return err.Error()
+}
+
+// record adds a request and its response, resp is nil when the request failed. Does nothing on a nil HAR.
+func (h *HAR) record(req *http.Request, resp *http.Response, body []byte, started time.Time, took time.Duration, err error) {
+	if h == nil {
+		return
+	}
+
+	elapsed := float64(took) / float64(time.Millisecond)
+	requestURL, query := h.redactURL(req.URL)
+	entry := harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         requestURL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harPair{},
+			Headers:     h.headers(req.Header),
+			QueryString: query,
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: harResponse{
+			Cookies:     []harPair{},
+			Headers:     []harPair{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Send: 0, Wait: elapsed, Receive: 0},
+	}
+	if resp != nil {
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = http.StatusText(resp.StatusCode)
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = h.headers(resp.Header)
+		entry.Response.Content = h.content(resp.Header.Get("Content-Type"), body)
+		entry.Response.BodySize = len(body)
+	}
+	if err != nil {
+		entry.Comment = errorComment(err, requestURL)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, entry)
+}
+
+// Len returns the number of requests captured
+func (h *HAR) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.entries)
+}
+
+// Reset drops every captured request
+func (h *HAR) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = nil
+}
+
+// JSON returns the captured requests as a HAR 1.2 document
+func (h *HAR) JSON() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var log harLog
+	log.Log.Version = "1.2"
+	log.Log.Creator = harCreator{Name: "godagpi", Version: "1"}
+	log.Log.Entries = append([]harEntry{}, h.entries...)
+
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// Save writes the captured requests to a .har file
+func (h *HAR) Save(path string) error {
+	data, err := h.JSON()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,199 @@
+package dagpi_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+// harFile is the part of a HAR document the tests look at
+type harFile struct {
+	Log struct {
+		Version string `json:"version"`
+		Entries []struct {
+			StartedDateTime string  `json:"startedDateTime"`
+			Time            float64 `json:"time"`
+			Comment         string  `json:"comment"`
+			Request         struct {
+				URL         string `json:"url"`
+				Headers     []struct{ Name, Value string }
+				QueryString []struct{ Name, Value string } `json:"queryString"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Content struct {
+					Size     int    `json:"size"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+					Comment  string `json:"comment"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+func decodeHAR(t *testing.T, har *dagpi.HAR) harFile {
+	t.Helper()
+
+	data, err := har.JSON()
+	if err != nil {
+		t.Fatalf("JSON() = %v", err)
+	}
+	var file harFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("JSON() isn't valid: %v", err)
+	}
+
+	return file
+}
+
+func TestHARCapturesTraffic(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	clock := dagpitest.NewClock(time.Time{})
+	server.Clock = clock
+
+	har := &dagpi.HAR{}
+	client := server.Client()
+	client.HAR = har
+	client.Clock = clock
+
+	// the server's latency passes on the same clock the HAR times requests with
+	server.SetLatency(250 * time.Millisecond)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Joke()
+		done <- err
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(250 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("Joke() = %v", err)
+	}
+
+	server.SetLatency(0)
+	server.Fail("image/pixel", dagpitest.Fault{Status: 500, Message: "down"})
+	_, _ = client.Pixelate(server.URL + "/assets/input.png")
+
+	if har.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", har.Len())
+	}
+	file := decodeHAR(t, har)
+	if file.Log.Version != "1.2" {
+		t.Errorf("version = %s, want 1.2", file.Log.Version)
+	}
+
+	joke := file.Log.Entries[0]
+	if joke.Time != 250 || joke.StartedDateTime != clock.Now().Add(-250*time.Millisecond).Format(time.RFC3339Nano) {
+		t.Errorf("the joke started at %s and took %vms, want the fake clock's 250ms", joke.StartedDateTime, joke.Time)
+	}
+	if joke.Response.Status != 200 || !strings.Contains(joke.Response.Content.Text, "joke") {
+		t.Errorf("the joke response is %d %q, want the json kept as text", joke.Response.Status, joke.Response.Content.Text)
+	}
+	for _, header := range joke.Request.Headers {
+		if header.Name == "Authorization" && header.Value != dagpi.HARRedacted {
+			t.Errorf("Authorization = %s, want it redacted", header.Value)
+		}
+	}
+
+	if pixel := file.Log.Entries[1]; pixel.Response.Status != 500 {
+		t.Errorf("the failed pixelate was recorded as %d, want 500", pixel.Response.Status)
+	}
+
+	path := filepath.Join(t.TempDir(), "traffic.har")
+	if err := har.Save(path); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	if saved, _ := ioutil.ReadFile(path); strings.Contains(string(saved), server.Token) {
+		t.Error("the saved HAR holds the token")
+	}
+
+	har.Reset()
+	if har.Len() != 0 {
+		t.Errorf("Len() after Reset() = %d, want 0", har.Len())
+	}
+}
+
+func TestHARRedactsAndTrims(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/input.png"
+
+	har := &dagpi.HAR{RedactParams: []string{"url"}, MaxImageBytes: 16}
+	client := server.Client()
+	client.HAR = har
+	image, err := client.Pixelate(input)
+	if err != nil {
+		t.Fatalf("Pixelate() = %v", err)
+	}
+
+	entry := decodeHAR(t, har).Log.Entries[0]
+	if strings.Contains(entry.Request.URL, "input.png") {
+		t.Errorf("url = %s, want the url param redacted", entry.Request.URL)
+	}
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Value != dagpi.HARRedacted {
+		t.Errorf("queryString = %v, want url redacted", entry.Request.QueryString)
+	}
+
+	content := entry.Response.Content
+	body, _ := base64.StdEncoding.DecodeString(content.Text)
+	if content.Encoding != "base64" || string(body) != string(image[:16]) {
+		t.Errorf("the image body is %d bytes of %s, want its first 16 in base64", len(body), content.Encoding)
+	}
+	if content.Size != len(image) || content.Comment == "" {
+		t.Errorf("content size %d with comment %q, want the full size %d and a note on the cut", content.Size, content.Comment, len(image))
+	}
+
+	left := &dagpi.HAR{MaxImageBytes: -1}
+	client.HAR = left
+	if _, err = client.Pixelate(input); err != nil {
+		t.Fatalf("Pixelate() = %v", err)
+	}
+	if content := decodeHAR(t, left).Log.Entries[0].Response.Content; content.Text != "" {
+		t.Errorf("the image body was kept with MaxImageBytes -1")
+	}
+}
+
+func TestHARRecordsTransportErrors(t *testing.T) {
+	server := dagpitest.NewServer("")
+	client := server.Client()
+	server.Close()
+
+	har := &dagpi.HAR{}
+	client.HAR = har
+	if _, err := client.Joke(); err == nil {
+		t.Fatal("Joke() on a closed server = nil, want an error")
+	}
+
+	entry := decodeHAR(t, har).Log.Entries[0]
+	if entry.Response.Status != 0 || entry.Comment == "" {
+		t.Errorf("the failed request has status %d and comment %q, want no response and the error", entry.Response.Status, entry.Comment)
+	}
+}
+
+func TestHARRedactsTransportErrors(t *testing.T) {
+	server := dagpitest.NewServer("")
+	client := server.Client()
+	server.Close()
+
+	har := &dagpi.HAR{RedactParams: []string{"url"}}
+	client.HAR = har
+	if _, err := client.Pixelate("https://secret.example/avatar.png"); err == nil {
+		t.Fatal("Pixelate() on a closed server = nil, want an error")
+	}
+
+	data, _ := har.JSON()
+	if strings.Contains(string(data), "secret.example") {
+		t.Errorf("the HAR holds the redacted url:\n%s", data)
+	}
+	if entry := decodeHAR(t, har).Log.Entries[0]; !strings.Contains(entry.Comment, dagpi.HARRedacted) {
+		t.Errorf("comment = %q, want the error with the redacted url", entry.Comment)
+	}
+}
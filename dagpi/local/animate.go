@@ -0,0 +1,233 @@
+package local
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+	"math/rand"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// Animation is the kind of movement Animate gives an image
+type Animation int
+
+const (
+	// AnimationRotate spins the image a full turn around its center
+	AnimationRotate Animation = iota
+	// AnimationShake jitters the image around its position
+	AnimationShake
+	// AnimationScroll slides the image sideways, wrapping around the edges
+	AnimationScroll
+	// AnimationTint pulses a colored overlay over the still image
+	AnimationTint
+	// AnimationRain draws rain streaks falling over the image
+	AnimationRain
+)
+
+// AnimationOptions configure Animate. The zero value makes a looping 16 frame animation with a full palette.
+type AnimationOptions struct {
+	Animation Animation
+
+	// Frames in the animation, defaults to 16
+	Frames int
+
+	// Delay of every frame in hundredths of a second, defaults to 5
+	Delay int
+
+	// LoopCount follows image/gif, 0 loops forever, -1 plays once and n plays n+1 times
+	LoopCount int
+
+	// Colors in the palette shared by every frame, 2 to 256, defaults to 256
+	Colors int
+
+	// Dither spreads the error of the reduced palette with Floyd-Steinberg dithering
+	Dither bool
+
+	// Tint is drawn over every frame with its alpha, AnimationTint pulses it from clear to Tint.
+	// Defaults to red for AnimationTint and nothing for the rest.
+	Tint color.NRGBA
+
+	// MaxSize is the longest side of the animation, bigger images are scaled down. Defaults to 256.
+	MaxSize int
+
+	// Seed makes the random parts of shake and rain repeatable
+	Seed int64
+}
+
+func (o AnimationOptions) withDefaults() AnimationOptions {
+	if o.Frames <= 0 {
+		o.Frames = 16
+	}
+	if o.Delay <= 0 {
+		o.Delay = 5
+	}
+	if o.Colors < 2 || o.Colors > 256 {
+		o.Colors = 256
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = 256
+	}
+	if o.Animation == AnimationTint && o.Tint == (color.NRGBA{}) {
+		o.Tint = color.NRGBA{R: 255, A: 160}
+	}
+
+	return o
+}
+
+// Animate turns a still image into an animated gif
+func Animate(img image.Image, opts AnimationOptions) *gif.GIF {
+	opts = opts.withDefaults()
+
+	src := imgutil.NRGBA(img)
+	w, h := imgutil.Fit(src.Bounds().Dx(), src.Bounds().Dy(), opts.MaxSize, opts.MaxSize)
+	if w != src.Bounds().Dx() || h != src.Bounds().Dy() {
+		src = imgutil.Resize(src, w, h)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var drops []rainDrop
+	if opts.Animation == AnimationRain {
+		drops = newRain(rng, w, h)
+	}
+
+	frames := make([]*image.NRGBA, opts.Frames)
+	for i := range frames {
+		t := float64(i) / float64(opts.Frames)
+
+		switch opts.Animation {
+		case AnimationRotate:
+			frames[i] = rotate(src, 2*math.Pi*t)
+		case AnimationShake:
+			amplitude := w / 20
+			if amplitude < 2 {
+				amplitude = 2
+			}
+			frames[i] = shift(src, rng.Intn(2*amplitude+1)-amplitude, rng.Intn(2*amplitude+1)-amplitude, false)
+		case AnimationScroll:
+			frames[i] = shift(src, int(t*float64(w)), 0, true)
+		case AnimationRain:
+			frames[i] = imgutil.NRGBA(src)
+			drawRain(frames[i], drops, t)
+		default:
+			frames[i] = imgutil.NRGBA(src)
+		}
+
+		tint := opts.Tint
+		if opts.Animation == AnimationTint {
+			// pulse from clear to the full tint and back
+			tint.A = uint8(float64(opts.Tint.A) * (1 - math.Cos(2*math.Pi*t)) / 2)
+		}
+		if tint.A > 0 {
+			fillRect(frames[i], 0, 0, w, h, tint)
+		}
+	}
+
+	palette := imgutil.Quantize(frames, opts.Colors)
+	anim := &gif.GIF{LoopCount: opts.LoopCount}
+	for _, frame := range frames {
+		anim.Image = append(anim.Image, imgutil.Paletted(frame, palette, opts.Dither))
+		anim.Delay = append(anim.Delay, opts.Delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+
+	return anim
+}
+
+// EncodeGIF encodes an animation
+func EncodeGIF(anim *gif.GIF) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gif.EncodeAll(&buf, anim)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// rotate turns src by angle radians around its center, pixels rotated in from outside are transparent
+func rotate(src *image.NRGBA, angle float64) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+	cx, cy := float64(w)/2, float64(h)/2
+	sin, cos := math.Sincos(angle)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			sx := int(math.Floor(cos*dx + sin*dy + cx))
+			sy := int(math.Floor(-sin*dx + cos*dy + cy))
+			if sx >= 0 && sx < w && sy >= 0 && sy < h {
+				dst.SetNRGBA(x, y, src.NRGBAAt(sx, sy))
+			}
+		}
+	}
+
+	return dst
+}
+
+// shift moves src by dx, dy. The uncovered edge either wraps around or repeats the nearest pixel.
+func shift(src *image.NRGBA, dx int, dy int, wrap bool) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := x-dx, y-dy
+			if wrap {
+				sx, sy = ((sx%w)+w)%w, ((sy%h)+h)%h
+			} else {
+				sx, sy = clampInt(sx, 0, w-1), clampInt(sy, 0, h-1)
+			}
+			dst.SetNRGBA(x, y, src.NRGBAAt(sx, sy))
+		}
+	}
+
+	return dst
+}
+
+func clampInt(v int, lo int, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
+
+type rainDrop struct {
+	x, y, length int
+}
+
+// newRain scatters drops over a w x h image, an empty image gets none
+func newRain(rng *rand.Rand, w int, h int) []rainDrop {
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	drops := make([]rainDrop, w*h/300+1)
+	for i := range drops {
+		drops[i] = rainDrop{x: rng.Intn(w), y: rng.Intn(h), length: h/20 + rng.Intn(h/20+1) + 2}
+	}
+
+	return drops
+}
+
+// drawRain draws every drop slanted and moved down by t of the image height, wrapping at the bottom
+func drawRain(dst *image.NRGBA, drops []rainDrop, t float64) {
+	w, h := dst.Bounds().Dx(), dst.Bounds().Dy()
+	streak := color.NRGBA{R: 200, G: 210, B: 230, A: 150}
+	fillRect(dst, 0, 0, w, h, color.NRGBA{R: 20, G: 30, B: 60, A: 60})
+
+	for _, drop := range drops {
+		y0 := drop.y + int(t*float64(h))
+		for i := 0; i < drop.length; i++ {
+			x, y := (drop.x+(y0+i)/4)%w, (y0+i)%h
+			blend(dst, x, y, streak)
+		}
+	}
+}
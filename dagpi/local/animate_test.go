@@ -0,0 +1,104 @@
+package local_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+)
+
+var animations = []local.Animation{local.AnimationRotate, local.AnimationShake, local.AnimationScroll, local.AnimationTint, local.AnimationRain}
+
+func TestAnimate(t *testing.T) {
+	img := gradient(64, 48)
+	for _, animation := range animations {
+		anim := local.Animate(img, local.AnimationOptions{Animation: animation, Frames: 8, Delay: 7, LoopCount: 2, Colors: 32})
+		if len(anim.Image) != 8 || len(anim.Delay) != 8 || len(anim.Disposal) != 8 {
+			t.Fatalf("animation %d has %d frames, %d delays and %d disposals, want 8 of each", animation, len(anim.Image), len(anim.Delay), len(anim.Disposal))
+		}
+		if anim.Delay[0] != 7 || anim.LoopCount != 2 {
+			t.Errorf("animation %d has delay %d and loop count %d, want 7 and 2", animation, anim.Delay[0], anim.LoopCount)
+		}
+		if anim.Image[0].Bounds() != img.Bounds() {
+			t.Errorf("animation %d frames are %v, want %v", animation, anim.Image[0].Bounds(), img.Bounds())
+		}
+		if len(anim.Image[0].Palette) > 32 {
+			t.Errorf("animation %d has %d colors, want at most 32", animation, len(anim.Image[0].Palette))
+		}
+	}
+}
+
+func TestAnimateDefaults(t *testing.T) {
+	anim := local.Animate(gradient(600, 300), local.AnimationOptions{})
+	if len(anim.Image) != 16 || anim.Delay[0] != 5 {
+		t.Errorf("got %d frames with delay %d, want 16 with delay 5", len(anim.Image), anim.Delay[0])
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 256 || b.Dy() != 128 {
+		t.Errorf("frames are %v, want the image scaled to 256x128", b)
+	}
+}
+
+func TestAnimateSeed(t *testing.T) {
+	img := gradient(64, 48)
+	encode := func(seed int64) []byte {
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, local.Animate(img, local.AnimationOptions{Animation: local.AnimationRain, Seed: seed})); err != nil {
+			t.Fatalf("encoding gif: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	if !bytes.Equal(encode(1), encode(1)) {
+		t.Error("the same seed gave different animations")
+	}
+	if bytes.Equal(encode(1), encode(2)) {
+		t.Error("different seeds gave the same rain")
+	}
+}
+
+func TestAnimateTint(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	anim := local.Animate(img, local.AnimationOptions{Animation: local.AnimationTint, Frames: 4, Tint: color.NRGBA{R: 255, A: 255}})
+
+	// the tint pulses from clear on the first frame to solid half way through
+	first := color.NRGBAModel.Convert(anim.Image[0].At(0, 0)).(color.NRGBA)
+	middle := color.NRGBAModel.Convert(anim.Image[2].At(0, 0)).(color.NRGBA)
+	if first.R != 0 || middle.R != 255 {
+		t.Errorf("red is %d on the first frame and %d half way, want 0 and 255", first.R, middle.R)
+	}
+}
+
+func TestAnimateEmptyImage(t *testing.T) {
+	for _, animation := range animations {
+		anim := local.Animate(image.NewNRGBA(image.Rect(0, 0, 0, 5)), local.AnimationOptions{Animation: animation})
+		if _, err := local.EncodeGIF(anim); err != nil {
+			t.Errorf("animation %d of an empty image = %v", animation, err)
+		}
+	}
+}
+
+func TestRendererAnimations(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	renderer := testRenderer()
+	input := server.URL + "/assets/input.png"
+	for name, render := range map[string]func(string) ([]byte, error){
+		"SpinImage": renderer.SpinImage,
+		"Shake":     renderer.Shake,
+		"Triggered": renderer.Triggered,
+		"Rain":      renderer.Rain,
+	} {
+		out, err := render(input)
+		if err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+		if _, err = gif.DecodeAll(bytes.NewReader(out)); err != nil {
+			t.Errorf("%s() didn't return a gif: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package local
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// Charsets for ASCIIOptions, ordered from the least to the most ink
+const (
+	CharsetASCII    = " .:-=+*#%@"
+	CharsetDetailed = " .'`^\",:;Il!i><~+_-?][}{1)(|/tfjrxnuvczXYUJCLQ0OZmwqpdbkhao*#MW&8%B@$"
+	CharsetBlocks   = " ░▒▓█"
+)
+
+// DefaultASCIIWidth is the number of columns used when ASCIIOptions.Width is not set
+const DefaultASCIIWidth = 80
+
+// ASCIIOptions configure ASCII art conversion
+type ASCIIOptions struct {
+	// Width is the number of characters per line, defaults to DefaultASCIIWidth
+	Width int
+
+	// Charset is ordered from the least to the most ink, defaults to CharsetASCII
+	Charset string
+
+	// Invert gives bright pixels the most ink, for light text on a dark background.
+	// By default dark pixels get the most ink, for dark text on a light background.
+	Invert bool
+
+	// Color draws every character of an ASCIIImage in the color of the pixels it stands for, ignored for text
+	Color bool
+}
+
+func (o ASCIIOptions) charset() []rune {
+	if o.Charset == "" {
+		return []rune(CharsetASCII)
+	}
+
+	return []rune(o.Charset)
+}
+
+// cells shrinks img to one pixel per character. Characters are about twice as tall as they are wide
+// so the image is squashed vertically to keep its proportions.
+func (o ASCIIOptions) cells(img image.Image) (*image.NRGBA, error) {
+	width := o.Width
+	if width <= 0 {
+		width = DefaultASCIIWidth
+	}
+
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, ErrEmptyImage
+	}
+	height := b.Dy() * width * glyphWidth / (b.Dx() * lineHeight)
+	if height < 1 {
+		height = 1
+	}
+
+	return imgutil.Resize(img, width, height), nil
+}
+
+func (o ASCIIOptions) char(c color.NRGBA, charset []rune) rune {
+	// transparent pixels blend into the background the art is meant for
+	background := 255
+	if o.Invert {
+		background = 0
+	}
+	brightness := (int(imgutil.Luma(c))*int(c.A) + background*(255-int(c.A))) / 255
+
+	ink := 255 - brightness
+	if o.Invert {
+		ink = brightness
+	}
+
+	return charset[ink*(len(charset)-1)/255]
+}
+
+// ASCII converts an image to ASCII art, one line of text per row of characters.
+// It returns ErrEmptyImage for an image without pixels.
+func ASCII(img image.Image, opts ASCIIOptions) (string, error) {
+	cells, err := opts.cells(img)
+	if err != nil {
+		return "", err
+	}
+	charset := opts.charset()
+	w, h := cells.Bounds().Dx(), cells.Bounds().Dy()
+
+	var sb strings.Builder
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sb.WriteRune(opts.char(cells.NRGBAAt(x, y), charset))
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String(), nil
+}
+
+// ASCIIImage draws the ASCII art of an image with the embedded font at scale times its size.
+// Text is black on white, or white on black when Invert is set. It returns ErrEmptyImage for an image without pixels.
+func ASCIIImage(img image.Image, opts ASCIIOptions, scale int) (*image.NRGBA, error) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	cells, err := opts.cells(img)
+	if err != nil {
+		return nil, err
+	}
+	charset := opts.charset()
+	w, h := cells.Bounds().Dx(), cells.Bounds().Dy()
+
+	ink, background := color.NRGBA{A: 255}, color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	if opts.Invert {
+		ink, background = background, ink
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w*advance*scale, h*lineHeight*scale))
+	fillRect(dst, 0, 0, dst.Bounds().Dx(), dst.Bounds().Dy(), background)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := cells.NRGBAAt(x, y)
+			textColor := ink
+			if opts.Color {
+				textColor = color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}
+			}
+			drawText(dst, x*advance*scale, y*lineHeight*scale, string(opts.char(c, charset)), scale, textColor)
+		}
+	}
+
+	return dst, nil
+}
+
+// Ascii Cool hackerman effect for an image. Takes the same input as dagpi.Client.Ascii and returns a png.
+func (r *Renderer) Ascii(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectAscii, url)
+}
+
+// AsciiText fetches the image at url and returns its ASCII art as text
+func (r *Renderer) AsciiText(url string, opts ASCIIOptions) (string, error) {
+	img, err := r.fetchImage(context.Background(), url)
+	if err != nil {
+		return "", err
+	}
+
+	return ASCII(img, opts)
+}
+
+// asciiImage is the Ascii effect, colored characters on black close to the size of the input like the API does.
+// The renderer refuses empty images before any effect runs, so ASCIIImage can't fail here.
+func asciiImage(img image.Image) image.Image {
+	width := img.Bounds().Dx() / advance
+	if width < 16 {
+		width = 16
+	}
+	if width > 200 {
+		width = 200
+	}
+
+	art, _ := ASCIIImage(img, ASCIIOptions{Width: width, Invert: true, Color: true}, 1)
+
+	return art
+}
@@ -0,0 +1,82 @@
+package local_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+)
+
+func TestASCII(t *testing.T) {
+	// left half black, right half white
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.NRGBA{A: 255}
+			if x >= 20 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	art, err := local.ASCII(img, local.ASCIIOptions{Width: 10})
+	if err != nil {
+		t.Fatalf("ASCII() = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+	if len(lines) == 0 || len(lines) >= 10 {
+		t.Fatalf("got %d lines, want fewer rows than columns for a square image", len(lines))
+	}
+	for _, line := range lines {
+		if line != "@@@@@     " {
+			t.Fatalf("line = %q, want dark ink on the left", line)
+		}
+	}
+
+	inverted, err := local.ASCII(img, local.ASCIIOptions{Width: 10, Invert: true})
+	if err != nil {
+		t.Fatalf("ASCII() = %v", err)
+	}
+	if !strings.HasPrefix(inverted, "     @@@@@\n") {
+		t.Errorf("inverted line = %q, want bright ink on the right", strings.SplitN(inverted, "\n", 2)[0])
+	}
+}
+
+func TestASCIIEmptyImage(t *testing.T) {
+	for _, img := range []image.Image{image.NewNRGBA(image.Rect(0, 0, 0, 10)), image.NewNRGBA(image.Rect(0, 0, 10, 0))} {
+		if _, err := local.ASCII(img, local.ASCIIOptions{}); !errors.Is(err, local.ErrEmptyImage) {
+			t.Errorf("ASCII() of a %v image = %v, want ErrEmptyImage", img.Bounds(), err)
+		}
+		if _, err := local.ASCIIImage(img, local.ASCIIOptions{}, 1); !errors.Is(err, local.ErrEmptyImage) {
+			t.Errorf("ASCIIImage() of a %v image = %v, want ErrEmptyImage", img.Bounds(), err)
+		}
+	}
+}
+
+func TestASCIIImage(t *testing.T) {
+	art, err := local.ASCIIImage(gradient(40, 40), local.ASCIIOptions{Width: 10}, 2)
+	if err != nil {
+		t.Fatalf("ASCIIImage() = %v", err)
+	}
+	if art.Bounds().Dx()%10 != 0 || art.Bounds().Dx() == 0 {
+		t.Errorf("ASCIIImage() is %d wide, want a multiple of the 10 columns", art.Bounds().Dx())
+	}
+}
+
+func TestAsciiText(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	text, err := testRenderer().AsciiText(server.URL+"/assets/input.png", local.ASCIIOptions{Width: 20})
+	if err != nil {
+		t.Fatalf("AsciiText() = %v", err)
+	}
+	if first := strings.SplitN(text, "\n", 2)[0]; len([]rune(first)) != 20 {
+		t.Errorf("first line has %d characters, want 20", len([]rune(first)))
+	}
+}
@@ -0,0 +1,236 @@
+package local
+
+import (
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// CaptchaCharset leaves out characters that are easy to mix up, like 0 and O or 1 and l
+const CaptchaCharset = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghkmnpqrstuvwxyz23456789"
+
+// CaptchaOptions configure a CaptchaGenerator, the zero value gives six characters on a 300x100 image
+type CaptchaOptions struct {
+	// Length of the answer, defaults to 6
+	Length int
+
+	// Charset the answer is picked from, defaults to CaptchaCharset
+	Charset string
+
+	// Width and Height of the image, default to 300 and 100
+	Width  int
+	Height int
+
+	// NoiseLines drawn across the text, defaults to 8. Use -1 for none.
+	NoiseLines int
+
+	// TTL is how long a challenge can be answered, zero never expires
+	TTL time.Duration
+
+	// Clock decides when challenges expire, defaults to dagpi.SystemClock
+	Clock dagpi.Clock
+
+	// Seed makes the generator produce the same challenges every run, for tests. Zero seeds from Clock.
+	Seed int64
+}
+
+// CaptchaChallenge is a generated captcha and its answer
+type CaptchaChallenge struct {
+	Answer string
+	// Image is the captcha as a png
+	Image []byte
+	// Expires is when the challenge can't be answered anymore, zero never expires
+	Expires time.Time
+
+	clock dagpi.Clock
+}
+
+// Check reports whether answer is right, ignoring case, and the challenge hasn't expired
+func (c *CaptchaChallenge) Check(answer string) bool {
+	if !c.Expires.IsZero() && c.now().After(c.Expires) {
+		return false
+	}
+
+	return strings.EqualFold(strings.TrimSpace(answer), c.Answer)
+}
+
+func (c *CaptchaChallenge) now() time.Time {
+	if c.clock == nil {
+		return dagpi.SystemClock.Now()
+	}
+
+	return c.clock.Now()
+}
+
+// Data returns the challenge in the same shape as dagpi.Client.Captcha, with the image as a data url
+func (c *CaptchaChallenge) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"image":  "data:image/png;base64," + base64.StdEncoding.EncodeToString(c.Image),
+		"answer": c.Answer,
+	}
+}
+
+// CaptchaGenerator renders captchas locally, it is safe for concurrent use
+type CaptchaGenerator struct {
+	opts CaptchaOptions
+	mu   sync.Mutex
+	rng  *rand.Rand
+}
+
+// NewCaptchaGenerator creates a CaptchaGenerator, see CaptchaOptions for the defaults
+func NewCaptchaGenerator(opts CaptchaOptions) *CaptchaGenerator {
+	if opts.Length <= 0 {
+		opts.Length = 6
+	}
+	if opts.Charset == "" {
+		opts.Charset = CaptchaCharset
+	}
+	if opts.Width <= 0 {
+		opts.Width = 300
+	}
+	if opts.Height <= 0 {
+		opts.Height = 100
+	}
+	if opts.NoiseLines == 0 {
+		opts.NoiseLines = 8
+	}
+	if opts.Clock == nil {
+		opts.Clock = dagpi.SystemClock
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = opts.Clock.Now().UnixNano()
+	}
+
+	return &CaptchaGenerator{opts: opts, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Captcha get a random captcha and answer. Returns the same shape as dagpi.Client.Captcha so it can stand in for it.
+func (g *CaptchaGenerator) Captcha() (interface{}, error) {
+	challenge, err := g.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge.Data(), nil
+}
+
+// Generate makes a new challenge with a random answer
+func (g *CaptchaGenerator) Generate() (*CaptchaChallenge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charset := []rune(g.opts.Charset)
+	answer := make([]rune, g.opts.Length)
+	for i := range answer {
+		answer[i] = charset[g.rng.Intn(len(charset))]
+	}
+
+	img := g.render(string(answer))
+	buffer, err := imgutil.EncodePNG(img)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &CaptchaChallenge{Answer: string(answer), Image: buffer, clock: g.opts.Clock}
+	if g.opts.TTL > 0 {
+		challenge.Expires = g.opts.Clock.Now().Add(g.opts.TTL)
+	}
+
+	return challenge, nil
+}
+
+// render draws every character rotated and jittered, warps the whole image along a sine wave and
+// scribbles noise over it, must hold g.mu
+func (g *CaptchaGenerator) render(answer string) *image.NRGBA {
+	w, h := g.opts.Width, g.opts.Height
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	background := color.NRGBA{R: uint8(220 + g.rng.Intn(36)), G: uint8(220 + g.rng.Intn(36)), B: uint8(220 + g.rng.Intn(36)), A: 255}
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	// speckles under the text
+	for i := 0; i < w*h/40; i++ {
+		img.SetNRGBA(g.rng.Intn(w), g.rng.Intn(h), g.randomColor(100, 200))
+	}
+
+	chars := []rune(answer)
+	cellWidth := w / (len(chars) + 1)
+	scale := h * 5 / (glyphHeight * 10)
+	if maxScale := cellWidth / glyphWidth; scale > maxScale {
+		scale = maxScale
+	}
+	if scale < 1 {
+		scale = 1
+	}
+
+	for i, r := range chars {
+		// each character gets its own canvas so it can be turned on its own
+		size := (glyphHeight + 4) * scale
+		glyph := image.NewNRGBA(image.Rect(0, 0, size, size))
+		drawText(glyph, (size-glyphWidth*scale)/2, (size-glyphHeight*scale)/2, string(r), scale, g.randomColor(0, 110))
+		glyph = rotate(glyph, (g.rng.Float64()-0.5)*0.8)
+
+		x := cellWidth/2 + i*cellWidth + (cellWidth-size)/2 + g.rng.Intn(scale+1) - scale/2
+		y := (h-size)/2 + g.rng.Intn(h/6+1) - h/12
+		draw.Draw(img, image.Rect(x, y, x+size, y+size), glyph, image.Point{}, draw.Over)
+	}
+
+	img = g.warp(img)
+
+	for i := 0; i < g.opts.NoiseLines; i++ {
+		drawLine(img, g.rng.Intn(w), g.rng.Intn(h), g.rng.Intn(w), g.rng.Intn(h), 1+g.rng.Intn(2), g.randomColor(0, 160))
+	}
+
+	return img
+}
+
+// warp shifts every column up or down along a random sine wave
+func (g *CaptchaGenerator) warp(src *image.NRGBA) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+	amplitude := float64(h) / 14
+	period := float64(w) / (1 + g.rng.Float64()*1.5)
+	phase := g.rng.Float64() * 2 * math.Pi
+
+	for x := 0; x < w; x++ {
+		dy := int(amplitude * math.Sin(2*math.Pi*float64(x)/period+phase))
+		for y := 0; y < h; y++ {
+			dst.SetNRGBA(x, y, src.NRGBAAt(x, clampInt(y+dy, 0, h-1)))
+		}
+	}
+
+	return dst
+}
+
+func (g *CaptchaGenerator) randomColor(lo int, hi int) color.NRGBA {
+	return color.NRGBA{
+		R: uint8(lo + g.rng.Intn(hi-lo)),
+		G: uint8(lo + g.rng.Intn(hi-lo)),
+		B: uint8(lo + g.rng.Intn(hi-lo)),
+		A: 255,
+	}
+}
+
+// drawLine draws a line thickness pixels wide between two points
+func drawLine(dst *image.NRGBA, x0 int, y0 int, x1 int, y1 int, thickness int, c color.NRGBA) {
+	steps := int(math.Max(math.Abs(float64(x1-x0)), math.Abs(float64(y1-y0))))
+	if steps == 0 {
+		steps = 1
+	}
+
+	for i := 0; i <= steps; i++ {
+		x := x0 + (x1-x0)*i/steps
+		y := y0 + (y1-y0)*i/steps
+		fillRect(dst, x-thickness/2, y-thickness/2, thickness, thickness, c)
+	}
+}
@@ -0,0 +1,129 @@
+package local_test
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+)
+
+func TestCaptchaGenerate(t *testing.T) {
+	captchas := local.NewCaptchaGenerator(local.CaptchaOptions{Length: 5, Width: 200, Height: 80, Seed: 1})
+	challenge, err := captchas.Generate()
+	if err != nil {
+		t.Fatalf("Generate() = %v", err)
+	}
+
+	if len(challenge.Answer) != 5 || strings.Trim(challenge.Answer, local.CaptchaCharset) != "" {
+		t.Errorf("Answer = %q, want 5 characters from CaptchaCharset", challenge.Answer)
+	}
+	img, err := png.Decode(bytes.NewReader(challenge.Image))
+	if err != nil {
+		t.Fatalf("Image isn't a png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 80 {
+		t.Errorf("Image is %v, want 200x80", b)
+	}
+
+	if !challenge.Check(" " + strings.ToLower(challenge.Answer) + " ") {
+		t.Error("Check() refused the answer in another case with spaces around it")
+	}
+	if challenge.Check(challenge.Answer + "x") {
+		t.Error("Check() accepted a wrong answer")
+	}
+}
+
+func TestCaptchaSeed(t *testing.T) {
+	a, _ := local.NewCaptchaGenerator(local.CaptchaOptions{Seed: 7}).Generate()
+	b, _ := local.NewCaptchaGenerator(local.CaptchaOptions{Seed: 7}).Generate()
+	if a.Answer != b.Answer || !bytes.Equal(a.Image, b.Image) {
+		t.Error("the same seed gave different captchas")
+	}
+
+	c, _ := local.NewCaptchaGenerator(local.CaptchaOptions{Seed: 8}).Generate()
+	if a.Answer == c.Answer {
+		t.Error("different seeds gave the same answer")
+	}
+}
+
+func TestCaptchaExpires(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	captchas := local.NewCaptchaGenerator(local.CaptchaOptions{TTL: time.Minute, Clock: clock, Seed: 1})
+	challenge, err := captchas.Generate()
+	if err != nil {
+		t.Fatalf("Generate() = %v", err)
+	}
+
+	if !challenge.Expires.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("Expires = %v, want a minute from now", challenge.Expires)
+	}
+	clock.Advance(time.Minute)
+	if !challenge.Check(challenge.Answer) {
+		t.Error("Check() refused the answer before it expired")
+	}
+	clock.Advance(time.Second)
+	if challenge.Check(challenge.Answer) {
+		t.Error("Check() accepted the answer after it expired")
+	}
+}
+
+func TestCaptchaSeedsFromClock(t *testing.T) {
+	generate := func() *local.CaptchaChallenge {
+		clock := dagpitest.NewClock(time.Time{})
+		challenge, err := local.NewCaptchaGenerator(local.CaptchaOptions{Clock: clock}).Generate()
+		if err != nil {
+			t.Fatalf("Generate() = %v", err)
+		}
+		return challenge
+	}
+
+	if a, b := generate(), generate(); a.Answer != b.Answer {
+		t.Errorf("two generators seeded from a clock at the same time answered %s and %s", a.Answer, b.Answer)
+	}
+}
+
+func TestCaptchaChallengeWithoutClock(t *testing.T) {
+	expired := &local.CaptchaChallenge{Answer: "abc", Expires: time.Now().Add(-time.Minute)}
+	if expired.Check("abc") {
+		t.Error("Check() accepted an expired challenge made without a generator")
+	}
+
+	open := &local.CaptchaChallenge{Answer: "abc", Expires: time.Now().Add(time.Hour)}
+	if !open.Check("abc") {
+		t.Error("Check() refused a challenge made without a generator before it expired")
+	}
+}
+
+func TestCaptchaStandsInForTheAPI(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	remote, err := server.Client().Captcha()
+	if err != nil {
+		t.Fatalf("Captcha() = %v", err)
+	}
+
+	server.Fail("data/captcha", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "down"})
+	if _, err = server.Client().Captcha(); err == nil {
+		t.Fatal("Captcha() from a failing API = nil, want an error")
+	}
+
+	generated, err := local.NewCaptchaGenerator(local.CaptchaOptions{Seed: 1}).Captcha()
+	if err != nil {
+		t.Fatalf("local Captcha() = %v", err)
+	}
+	data := generated.(map[string]interface{})
+	for key := range remote.(map[string]interface{}) {
+		if _, ok := data[key]; !ok {
+			t.Errorf("the local captcha has no %q like the API's", key)
+		}
+	}
+	if image, _ := data["image"].(string); !strings.HasPrefix(image, "data:image/png;base64,") {
+		t.Errorf("image = %.40q, want a png data url", image)
+	}
+}
@@ -0,0 +1,261 @@
+package local
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// Invert inverts every color of an image, keeping its transparency
+func Invert(img image.Image) *image.NRGBA {
+	dst := imgutil.NRGBA(img)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		dst.Pix[i] = 255 - dst.Pix[i]
+		dst.Pix[i+1] = 255 - dst.Pix[i+1]
+		dst.Pix[i+2] = 255 - dst.Pix[i+2]
+	}
+
+	return dst
+}
+
+// Mirror mirrors an image along the y-axis
+func Mirror(img image.Image) *image.NRGBA {
+	src := imgutil.NRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetNRGBA(w-1-x, y, src.NRGBAAt(x, y))
+		}
+	}
+
+	return dst
+}
+
+// FlipImage flips an image upside down
+func FlipImage(img image.Image) *image.NRGBA {
+	src := imgutil.NRGBA(img)
+	h := src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+	for y := 0; y < h; y++ {
+		copy(dst.Pix[dst.PixOffset(0, h-1-y):], src.Pix[src.PixOffset(0, y):src.PixOffset(0, y)+src.Stride])
+	}
+
+	return dst
+}
+
+// Pixelate fills every block x block square with its average color, a block of 0 picks one from the image size
+func Pixelate(img image.Image, block int) *image.NRGBA {
+	src := imgutil.NRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if block <= 0 {
+		block = defaultBlock(w, h)
+	}
+
+	small := imgutil.Resize(src, (w+block-1)/block, (h+block-1)/block)
+	dst := image.NewNRGBA(src.Bounds())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetNRGBA(x, y, small.NRGBAAt(x/block, y/block))
+		}
+	}
+
+	return dst
+}
+
+// roughly what the API uses, about 32 blocks along the longest side
+func defaultBlock(w int, h int) int {
+	longest := w
+	if h > longest {
+		longest = h
+	}
+	if longest < 64 {
+		return 2
+	}
+
+	return longest / 32
+}
+
+// Blur blurs an image with three box blur passes, which comes close to a gaussian blur.
+// A radius of 0 picks one from the image size.
+func Blur(img image.Image, radius int) *image.NRGBA {
+	dst := imgutil.NRGBA(img)
+	if radius <= 0 {
+		radius = defaultBlock(dst.Bounds().Dx(), dst.Bounds().Dy()) / 2
+		if radius < 1 {
+			radius = 1
+		}
+	}
+
+	for pass := 0; pass < 3; pass++ {
+		dst = boxBlur(dst, radius, true)
+		dst = boxBlur(dst, radius, false)
+	}
+
+	return dst
+}
+
+// one direction of a box blur using a running sum per row or column
+func boxBlur(src *image.NRGBA, radius int, horizontal bool) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+
+	lines, length := h, w
+	if !horizontal {
+		lines, length = w, h
+	}
+	at := func(line int, i int) int {
+		if i < 0 {
+			i = 0
+		}
+		if i >= length {
+			i = length - 1
+		}
+		if horizontal {
+			return src.PixOffset(i, line)
+		}
+
+		return src.PixOffset(line, i)
+	}
+
+	size := 2*radius + 1
+	for line := 0; line < lines; line++ {
+		var sum [4]int
+		for i := -radius; i <= radius; i++ {
+			p := at(line, i)
+			for ch := 0; ch < 4; ch++ {
+				sum[ch] += int(src.Pix[p+ch])
+			}
+		}
+
+		for i := 0; i < length; i++ {
+			var o int
+			if horizontal {
+				o = dst.PixOffset(i, line)
+			} else {
+				o = dst.PixOffset(line, i)
+			}
+			for ch := 0; ch < 4; ch++ {
+				dst.Pix[o+ch] = uint8(sum[ch] / size)
+			}
+
+			out, in := at(line, i-radius), at(line, i+radius+1)
+			for ch := 0; ch < 4; ch++ {
+				sum[ch] += int(src.Pix[in+ch]) - int(src.Pix[out+ch])
+			}
+		}
+	}
+
+	return dst
+}
+
+// Sepia gives an image the brown tone of an old photo
+func Sepia(img image.Image) *image.NRGBA {
+	dst := imgutil.NRGBA(img)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		r, g, b := int(dst.Pix[i]), int(dst.Pix[i+1]), int(dst.Pix[i+2])
+		dst.Pix[i] = imgutil.Clamp((393*r + 769*g + 189*b) / 1000)
+		dst.Pix[i+1] = imgutil.Clamp((349*r + 686*g + 168*b) / 1000)
+		dst.Pix[i+2] = imgutil.Clamp((272*r + 534*g + 131*b) / 1000)
+	}
+
+	return dst
+}
+
+// Grayscale converts an image to shades of gray, keeping its transparency
+func Grayscale(img image.Image) *image.NRGBA {
+	dst := imgutil.NRGBA(img)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		l := imgutil.Luma(color.NRGBA{R: dst.Pix[i], G: dst.Pix[i+1], B: dst.Pix[i+2]})
+		dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2] = l, l, l
+	}
+
+	return dst
+}
+
+// Sobel draws the edges of an image found with the sobel operator, white on black
+func Sobel(img image.Image) *image.NRGBA {
+	gray := Grayscale(img)
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	dst := image.NewNRGBA(gray.Bounds())
+
+	at := func(x int, y int) int {
+		if x < 0 {
+			x = 0
+		}
+		if x >= w {
+			x = w - 1
+		}
+		if y < 0 {
+			y = 0
+		}
+		if y >= h {
+			y = h - 1
+		}
+
+		return int(gray.Pix[gray.PixOffset(x, y)])
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := -at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1) + at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1)
+			gy := -at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1) + at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1)
+			v := imgutil.Clamp(int(math.Sqrt(float64(gx*gx + gy*gy))))
+			dst.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: gray.Pix[gray.PixOffset(x, y)+3]})
+		}
+	}
+
+	return dst
+}
+
+// Posterize reduces every color channel to the given number of levels, 0 uses 4 and 256 or more keeps every value
+func Posterize(img image.Image, levels int) *image.NRGBA {
+	if levels < 2 {
+		levels = 4
+	}
+	if levels > 256 {
+		levels = 256
+	}
+
+	// round to the nearest level, then spread the levels evenly over 0 to 255
+	var table [256]uint8
+	top := levels - 1
+	for v := range table {
+		level := (v*top + 127) / 255
+		table[v] = uint8((level*255 + top/2) / top)
+	}
+
+	dst := imgutil.NRGBA(img)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		dst.Pix[i] = table[dst.Pix[i]]
+		dst.Pix[i+1] = table[dst.Pix[i+1]]
+		dst.Pix[i+2] = table[dst.Pix[i+2]]
+	}
+
+	return dst
+}
+
+// Charcoal turns an image into a charcoal drawing, dark strokes along its edges on a light background
+func Charcoal(img image.Image) *image.NRGBA {
+	edges := Sobel(Blur(img, 1))
+
+	// stretch the edges so faint ones still show up as strokes
+	var brightest uint8
+	for i := 0; i < len(edges.Pix); i += 4 {
+		if edges.Pix[i] > brightest {
+			brightest = edges.Pix[i]
+		}
+	}
+	if brightest == 0 {
+		brightest = 1
+	}
+
+	for i := 0; i < len(edges.Pix); i += 4 {
+		v := 255 - imgutil.Clamp(int(edges.Pix[i])*255/int(brightest))
+		edges.Pix[i], edges.Pix[i+1], edges.Pix[i+2] = v, v, v
+	}
+
+	return edges
+}
@@ -0,0 +1,251 @@
+package local
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// The font used for every bit of text the renderer draws is a classic 5x7 pixel font, embedded below so
+// rendering never needs a font file. Text is scaled up by whole pixels to get bigger sizes.
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+	// advance and lineHeight include the spacing between characters and lines
+	advance    = glyphWidth + 1
+	lineHeight = glyphHeight + 2
+)
+
+// glyphs for ' ' to '~', one byte per column with the top row in the lowest bit
+var glyphs = [95][glyphWidth]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00}, // ' '
+	{0x00, 0x00, 0x5F, 0x00, 0x00}, // !
+	{0x00, 0x07, 0x00, 0x07, 0x00}, // "
+	{0x14, 0x7F, 0x14, 0x7F, 0x14}, // #
+	{0x24, 0x2A, 0x7F, 0x2A, 0x12}, // $
+	{0x23, 0x13, 0x08, 0x64, 0x62}, // %
+	{0x36, 0x49, 0x55, 0x22, 0x50}, // &
+	{0x00, 0x05, 0x03, 0x00, 0x00}, // '
+	{0x00, 0x1C, 0x22, 0x41, 0x00}, // (
+	{0x00, 0x41, 0x22, 0x1C, 0x00}, // )
+	{0x08, 0x2A, 0x1C, 0x2A, 0x08}, // *
+	{0x08, 0x08, 0x3E, 0x08, 0x08}, // +
+	{0x00, 0x50, 0x30, 0x00, 0x00}, // ,
+	{0x08, 0x08, 0x08, 0x08, 0x08}, // -
+	{0x00, 0x60, 0x60, 0x00, 0x00}, // .
+	{0x20, 0x10, 0x08, 0x04, 0x02}, // /
+	{0x3E, 0x51, 0x49, 0x45, 0x3E}, // 0
+	{0x00, 0x42, 0x7F, 0x40, 0x00}, // 1
+	{0x42, 0x61, 0x51, 0x49, 0x46}, // 2
+	{0x21, 0x41, 0x45, 0x4B, 0x31}, // 3
+	{0x18, 0x14, 0x12, 0x7F, 0x10}, // 4
+	{0x27, 0x45, 0x45, 0x45, 0x39}, // 5
+	{0x3C, 0x4A, 0x49, 0x49, 0x30}, // 6
+	{0x01, 0x71, 0x09, 0x05, 0x03}, // 7
+	{0x36, 0x49, 0x49, 0x49, 0x36}, // 8
+	{0x06, 0x49, 0x49, 0x29, 0x1E}, // 9
+	{0x00, 0x36, 0x36, 0x00, 0x00}, // :
+	{0x00, 0x56, 0x36, 0x00, 0x00}, // ;
+	{0x08, 0x14, 0x22, 0x41, 0x00}, // <
+	{0x14, 0x14, 0x14, 0x14, 0x14}, // =
+	{0x00, 0x41, 0x22, 0x14, 0x08}, // >
+	{0x02, 0x01, 0x51, 0x09, 0x06}, // ?
+	{0x32, 0x49, 0x79, 0x41, 0x3E}, // @
+	{0x7E, 0x11, 0x11, 0x11, 0x7E}, // A
+	{0x7F, 0x49, 0x49, 0x49, 0x36}, // B
+	{0x3E, 0x41, 0x41, 0x41, 0x22}, // C
+	{0x7F, 0x41, 0x41, 0x22, 0x1C}, // D
+	{0x7F, 0x49, 0x49, 0x49, 0x41}, // E
+	{0x7F, 0x09, 0x09, 0x09, 0x01}, // F
+	{0x3E, 0x41, 0x49, 0x49, 0x7A}, // G
+	{0x7F, 0x08, 0x08, 0x08, 0x7F}, // H
+	{0x00, 0x41, 0x7F, 0x41, 0x00}, // I
+	{0x20, 0x40, 0x41, 0x3F, 0x01}, // J
+	{0x7F, 0x08, 0x14, 0x22, 0x41}, // K
+	{0x7F, 0x40, 0x40, 0x40, 0x40}, // L
+	{0x7F, 0x02, 0x0C, 0x02, 0x7F}, // M
+	{0x7F, 0x04, 0x08, 0x10, 0x7F}, // N
+	{0x3E, 0x41, 0x41, 0x41, 0x3E}, // O
+	{0x7F, 0x09, 0x09, 0x09, 0x06}, // P
+	{0x3E, 0x41, 0x51, 0x21, 0x5E}, // Q
+	{0x7F, 0x09, 0x19, 0x29, 0x46}, // R
+	{0x46, 0x49, 0x49, 0x49, 0x31}, // S
+	{0x01, 0x01, 0x7F, 0x01, 0x01}, // T
+	{0x3F, 0x40, 0x40, 0x40, 0x3F}, // U
+	{0x1F, 0x20, 0x40, 0x20, 0x1F}, // V
+	{0x3F, 0x40, 0x38, 0x40, 0x3F}, // W
+	{0x63, 0x14, 0x08, 0x14, 0x63}, // X
+	{0x07, 0x08, 0x70, 0x08, 0x07}, // Y
+	{0x61, 0x51, 0x49, 0x45, 0x43}, // Z
+	{0x00, 0x7F, 0x41, 0x41, 0x00}, // [
+	{0x02, 0x04, 0x08, 0x10, 0x20}, // \
+	{0x00, 0x41, 0x41, 0x7F, 0x00}, // ]
+	{0x04, 0x02, 0x01, 0x02, 0x04}, // ^
+	{0x40, 0x40, 0x40, 0x40, 0x40}, // _
+	{0x00, 0x01, 0x02, 0x04, 0x00}, // `
+	{0x20, 0x54, 0x54, 0x54, 0x78}, // a
+	{0x7F, 0x48, 0x44, 0x44, 0x38}, // b
+	{0x38, 0x44, 0x44, 0x44, 0x20}, // c
+	{0x38, 0x44, 0x44, 0x48, 0x7F}, // d
+	{0x38, 0x54, 0x54, 0x54, 0x18}, // e
+	{0x08, 0x7E, 0x09, 0x01, 0x02}, // f
+	{0x0C, 0x52, 0x52, 0x52, 0x3E}, // g
+	{0x7F, 0x08, 0x04, 0x04, 0x78}, // h
+	{0x00, 0x44, 0x7D, 0x40, 0x00}, // i
+	{0x20, 0x40, 0x44, 0x3D, 0x00}, // j
+	{0x7F, 0x10, 0x28, 0x44, 0x00}, // k
+	{0x00, 0x41, 0x7F, 0x40, 0x00}, // l
+	{0x7C, 0x04, 0x18, 0x04, 0x78}, // m
+	{0x7C, 0x08, 0x04, 0x04, 0x78}, // n
+	{0x38, 0x44, 0x44, 0x44, 0x38}, // o
+	{0x7C, 0x14, 0x14, 0x14, 0x08}, // p
+	{0x08, 0x14, 0x14, 0x18, 0x7C}, // q
+	{0x7C, 0x08, 0x04, 0x04, 0x08}, // r
+	{0x48, 0x54, 0x54, 0x54, 0x20}, // s
+	{0x04, 0x3F, 0x44, 0x40, 0x20}, // t
+	{0x3C, 0x40, 0x40, 0x20, 0x7C}, // u
+	{0x1C, 0x20, 0x40, 0x20, 0x1C}, // v
+	{0x3C, 0x40, 0x30, 0x40, 0x3C}, // w
+	{0x44, 0x28, 0x10, 0x28, 0x44}, // x
+	{0x0C, 0x50, 0x50, 0x50, 0x3C}, // y
+	{0x44, 0x64, 0x54, 0x4C, 0x44}, // z
+	{0x00, 0x08, 0x36, 0x41, 0x00}, // {
+	{0x00, 0x00, 0x7F, 0x00, 0x00}, // |
+	{0x00, 0x41, 0x36, 0x08, 0x00}, // }
+	{0x08, 0x04, 0x08, 0x10, 0x08}, // ~
+}
+
+// shade blocks are drawn as a pixel pattern covering the whole character cell
+var shades = map[rune]int{'░': 1, '▒': 2, '▓': 3, '█': 4}
+
+// pixel reports whether the glyph for r has ink at column x, row y of its advance x lineHeight cell
+func pixel(r rune, x int, y int) bool {
+	if level, ok := shades[r]; ok {
+		switch level {
+		case 1:
+			return x%2 == 0 && y%2 == 0
+		case 2:
+			return (x+y)%2 == 0
+		case 3:
+			return !(x%2 == 0 && y%2 == 0)
+		default:
+			return true
+		}
+	}
+
+	if x >= glyphWidth || y >= glyphHeight {
+		return false
+	}
+	if r < ' ' || r > '~' {
+		r = '?'
+	}
+
+	return glyphs[r-' '][x]&(1<<uint(y)) != 0
+}
+
+// textWidth is the width in pixels of a single line of text at scale
+func textWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+
+	return (n*advance - 1) * scale
+}
+
+// textHeight is the height in pixels of lines of text at scale
+func textHeight(lines int, scale int) int {
+	if lines == 0 {
+		return 0
+	}
+
+	return (lines*lineHeight - (lineHeight - glyphHeight)) * scale
+}
+
+// drawText draws a single line of text with its top left corner at x, y
+func drawText(dst *image.NRGBA, x int, y int, text string, scale int, c color.NRGBA) {
+	for i, r := range []rune(text) {
+		left := x + i*advance*scale
+		for gy := 0; gy < lineHeight; gy++ {
+			for gx := 0; gx < advance; gx++ {
+				if !pixel(r, gx, gy) {
+					continue
+				}
+				fillRect(dst, left+gx*scale, y+gy*scale, scale, scale, c)
+			}
+		}
+	}
+}
+
+// drawOutlinedText draws text with an outline of stroke pixels around every glyph, the way meme captions look
+func drawOutlinedText(dst *image.NRGBA, x int, y int, text string, scale int, fill color.NRGBA, outline color.NRGBA, stroke int) {
+	for dy := -stroke; dy <= stroke; dy++ {
+		for dx := -stroke; dx <= stroke; dx++ {
+			if dx != 0 || dy != 0 {
+				drawText(dst, x+dx, y+dy, text, scale, outline)
+			}
+		}
+	}
+	drawText(dst, x, y, text, scale, fill)
+}
+
+func fillRect(dst *image.NRGBA, x int, y int, w int, h int, c color.NRGBA) {
+	rect := image.Rect(x, y, x+w, y+h).Intersect(dst.Bounds())
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			blend(dst, px, py, c)
+		}
+	}
+}
+
+// blend draws c over the pixel at x, y
+func blend(dst *image.NRGBA, x int, y int, c color.NRGBA) {
+	if c.A == 255 {
+		dst.SetNRGBA(x, y, c)
+		return
+	}
+
+	i := dst.PixOffset(x, y)
+	a := int(c.A)
+	for ch, v := range []uint8{c.R, c.G, c.B} {
+		dst.Pix[i+ch] = uint8((int(v)*a + int(dst.Pix[i+ch])*(255-a)) / 255)
+	}
+	dst.Pix[i+3] = uint8(a + int(dst.Pix[i+3])*(255-a)/255)
+}
+
+// wrapText splits text into lines no wider than maxWidth pixels at scale, breaking between words
+// and only inside a word when it doesn't fit on a line by itself
+func wrapText(text string, scale int, maxWidth int) []string {
+	maxChars := (maxWidth/scale + 1) / advance
+	if maxChars < 1 {
+		maxChars = 1
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > maxChars {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, string([]rune(word)[:maxChars]))
+				word = string([]rune(word)[maxChars:])
+			}
+
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= maxChars:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
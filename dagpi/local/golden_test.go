@@ -0,0 +1,32 @@
+package local_test
+
+import (
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/phash"
+	"github.com/beamer64/godagpi/dagpi/phash/phashtest"
+)
+
+func TestGolden(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	input := server.URL + "/assets/golden.png"
+	renderer := testRenderer()
+
+	// gifs are compared by their first frame
+	for golden, render := range map[string]func(string) ([]byte, error){
+		"sepia.png":     renderer.Sepia,
+		"sobel.png":     renderer.Sobel,
+		"triggered.gif": renderer.Triggered,
+	} {
+		t.Run(golden, func(t *testing.T) {
+			got, err := render(input)
+			if err != nil {
+				t.Fatalf("rendering %s: %v", golden, err)
+			}
+
+			phashtest.AssertGolden(t, got, "testdata/"+golden, phash.DefaultMaxDistance)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+var (
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.NRGBA{A: 255}
+)
+
+// memes are drawn at least this wide so captions stay readable on small avatars
+const minMemeWidth = 400
+
+// fitText wraps text to maxWidth at the largest scale up to maxScale whose lines fit in maxHeight
+func fitText(text string, maxWidth int, maxHeight int, maxScale int) ([]string, int) {
+	for scale := maxScale; scale > 1; scale-- {
+		lines := wrapText(text, scale, maxWidth)
+		if textHeight(len(lines), scale) <= maxHeight {
+			return lines, scale
+		}
+	}
+
+	return wrapText(text, 1, maxWidth), 1
+}
+
+// drawLines draws lines centered horizontally in width starting at y, outlined when stroke is more than 0
+func drawLines(dst *image.NRGBA, lines []string, scale int, y int, width int, fill color.NRGBA, stroke int) {
+	for i, line := range lines {
+		x := (width - textWidth(line, scale)) / 2
+		lineY := y + i*lineHeight*scale
+		if stroke > 0 {
+			drawOutlinedText(dst, x, lineY, line, scale, fill, black, stroke)
+		} else {
+			drawText(dst, x, lineY, line, scale, fill)
+		}
+	}
+}
+
+// memeBase copies img scaled up to the minimum meme width, an empty image becomes a blank square
+// so the captions still have somewhere to go. Scaling a narrow, tall image up can ask for far more
+// pixels than were decoded, so the scaled size is held to imgutil.MaxPixels.
+func memeBase(img image.Image) (*image.NRGBA, error) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w == 0 || h == 0 {
+		return image.NewNRGBA(image.Rect(0, 0, minMemeWidth, minMemeWidth)), nil
+	}
+	if w >= minMemeWidth {
+		return imgutil.NRGBA(img), nil
+	}
+
+	scaledHeight := int64(h) * minMemeWidth / int64(w)
+	if minMemeWidth*scaledHeight > imgutil.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d scales up to %dx%d", imgutil.ErrTooLarge, w, h, minMemeWidth, scaledHeight)
+	}
+
+	return imgutil.Resize(img, minMemeWidth, int(scaledHeight)), nil
+}
+
+// Retromeme draws the classic white outlined captions over the top and bottom of an image
+func Retromeme(img image.Image, topText string, bottomText string) (*image.NRGBA, error) {
+	dst, err := memeBase(img)
+	if err != nil {
+		return nil, err
+	}
+	w, h := dst.Bounds().Dx(), dst.Bounds().Dy()
+	margin := w / 40
+	maxScale := w / 100
+	if maxScale < 2 {
+		maxScale = 2
+	}
+
+	if topText != "" {
+		lines, scale := fitText(strings.ToUpper(topText), w-2*margin, h/3, maxScale)
+		drawLines(dst, lines, scale, margin, w, white, scale/2+1)
+	}
+	if bottomText != "" {
+		lines, scale := fitText(strings.ToUpper(bottomText), w-2*margin, h/3, maxScale)
+		drawLines(dst, lines, scale, h-margin-textHeight(len(lines), scale), w, white, scale/2+1)
+	}
+
+	return dst, nil
+}
+
+// Motivational puts an image in a white frame on a black poster with a big title and a smaller line under it
+func Motivational(img image.Image, topText string, bottomText string) (*image.NRGBA, error) {
+	picture, err := memeBase(img)
+	if err != nil {
+		return nil, err
+	}
+	pw, ph := picture.Bounds().Dx(), picture.Bounds().Dy()
+
+	pad := pw / 10
+	border := pw/200 + 2
+	w := pw + 2*pad
+	maxWidth := w - 2*pad
+
+	topLines, topScale := fitText(strings.ToUpper(topText), maxWidth, ph/3, w/90+1)
+	bottomLines, bottomScale := fitText(bottomText, maxWidth, ph/4, topScale/2+1)
+
+	h := pad + ph + pad/2 + textHeight(len(topLines), topScale) + pad/3 + textHeight(len(bottomLines), bottomScale) + pad/2
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(black), image.Point{}, draw.Src)
+
+	fillRect(dst, pad-2*border, pad-2*border, pw+4*border, ph+4*border, white)
+	fillRect(dst, pad-border, pad-border, pw+2*border, ph+2*border, black)
+	draw.Draw(dst, image.Rect(pad, pad, pad+pw, pad+ph), picture, image.Point{}, draw.Over)
+
+	y := pad + ph + pad/2
+	drawLines(dst, topLines, topScale, y, w, white, 0)
+	y += textHeight(len(topLines), topScale) + pad/3
+	drawLines(dst, bottomLines, bottomScale, y, w, white, 0)
+
+	return dst, nil
+}
+
+// Modernmeme adds a white band above an image with the caption in black, the way memes are posted today
+func Modernmeme(img image.Image, text string) (*image.NRGBA, error) {
+	picture, err := memeBase(img)
+	if err != nil {
+		return nil, err
+	}
+	w, ph := picture.Bounds().Dx(), picture.Bounds().Dy()
+	margin := w / 25
+
+	lines, scale := fitText(text, w-2*margin, ph, w/150+1)
+	band := textHeight(len(lines), scale) + 2*margin
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, band+ph))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(0, band, w, band+ph), picture, image.Point{}, draw.Over)
+
+	// modern memes are left aligned
+	for i, line := range lines {
+		drawText(dst, margin, margin+i*lineHeight*scale, line, scale, black)
+	}
+
+	return dst, nil
+}
+
+// Retromeme The good old memes. Generated. Takes the same input as dagpi.Client.Retromeme.
+func (r *Renderer) Retromeme(url string, topText string, bottomText string) ([]byte, error) {
+	return r.retromeme(context.Background(), url, topText, bottomText)
+}
+
+func (r *Renderer) retromeme(ctx context.Context, url string, topText string, bottomText string) ([]byte, error) {
+	return r.renderFetched(ctx, url, func(img image.Image) (image.Image, error) {
+		return Retromeme(img, topText, bottomText)
+	})
+}
+
+// Motivational The black background with top and bottom motivational text. Takes the same input as dagpi.Client.Motivational.
+func (r *Renderer) Motivational(url string, topText string, bottomText string) ([]byte, error) {
+	return r.motivational(context.Background(), url, topText, bottomText)
+}
+
+func (r *Renderer) motivational(ctx context.Context, url string, topText string, bottomText string) ([]byte, error) {
+	return r.renderFetched(ctx, url, func(img image.Image) (image.Image, error) {
+		return Motivational(img, topText, bottomText)
+	})
+}
+
+// Modernmeme A modern meme with the caption above the image. Takes the same input as dagpi.Client.Modernmeme.
+func (r *Renderer) Modernmeme(url string, text string) ([]byte, error) {
+	return r.modernmeme(context.Background(), url, text)
+}
+
+func (r *Renderer) modernmeme(ctx context.Context, url string, text string) ([]byte, error) {
+	return r.renderFetched(ctx, url, func(img image.Image) (image.Image, error) {
+		return Modernmeme(img, text)
+	})
+}
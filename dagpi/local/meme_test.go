@@ -0,0 +1,140 @@
+package local_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// memes draws every kind of meme over img
+func memes(t *testing.T, img image.Image, top string, bottom string) map[string]*image.NRGBA {
+	t.Helper()
+
+	results := map[string]*image.NRGBA{}
+	for name, draw := range map[string]func() (*image.NRGBA, error){
+		"Retromeme":    func() (*image.NRGBA, error) { return local.Retromeme(img, top, bottom) },
+		"Motivational": func() (*image.NRGBA, error) { return local.Motivational(img, top, bottom) },
+		"Modernmeme":   func() (*image.NRGBA, error) { return local.Modernmeme(img, top) },
+	} {
+		meme, err := draw()
+		if err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+		results[name] = meme
+	}
+
+	return results
+}
+
+// meme returns a func that fails the test when a meme couldn't be drawn, so meme(t)(local.Retromeme(...)) reads in one line
+func meme(t *testing.T) func(*image.NRGBA, error) *image.NRGBA {
+	return func(img *image.NRGBA, err error) *image.NRGBA {
+		t.Helper()
+
+		if err != nil {
+			t.Fatalf("drawing the meme = %v", err)
+		}
+
+		return img
+	}
+}
+
+func TestMemesScaleUpSmallImages(t *testing.T) {
+	img := gradient(100, 50)
+	for name, result := range memes(t, img, "Teamwork", "Because nobody can be blamed alone.") {
+		if result.Bounds().Dx() < 400 {
+			t.Errorf("%s is %d wide, want at least 400", name, result.Bounds().Dx())
+		}
+	}
+
+	if b := meme(t)(local.Retromeme(img, "", "")).Bounds(); b.Dx() != 400 || b.Dy() != 200 {
+		t.Errorf("Retromeme() is %v, want the image scaled to 400x200", b)
+	}
+}
+
+func TestRetromemeDrawsText(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 400))
+	captioned := meme(t)(local.Retromeme(img, "top", ""))
+
+	white := 0
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 400; x++ {
+			if captioned.NRGBAAt(x, y) == (color.NRGBA{R: 255, G: 255, B: 255, A: 255}) {
+				white++
+			}
+		}
+	}
+	if white == 0 {
+		t.Error("no white caption was drawn at the top")
+	}
+	if !bytes.Equal(meme(t)(local.Retromeme(img, "", "")).Pix, img.Pix) {
+		t.Error("Retromeme() without captions changed the image")
+	}
+}
+
+func TestModernmemeGrowsWithText(t *testing.T) {
+	img := gradient(400, 300)
+	short := meme(t)(local.Modernmeme(img, "short"))
+	long := meme(t)(local.Modernmeme(img, strings.Repeat("a much longer caption that has to wrap ", 5)))
+	if short.Bounds().Dy() <= 300 {
+		t.Errorf("Modernmeme() is %d tall, want a band above the 300 pixel image", short.Bounds().Dy())
+	}
+	if long.Bounds().Dy() <= short.Bounds().Dy() {
+		t.Error("a long caption didn't make the band taller")
+	}
+}
+
+func TestMemesEmptyImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 0, 10))
+	for name, result := range memes(t, img, "top", "bottom") {
+		if result.Bounds().Empty() {
+			t.Errorf("%s of an empty image is empty, want a blank meme", name)
+		}
+	}
+}
+
+func TestMemesRefuseHugeScaledImages(t *testing.T) {
+	// a 1 pixel wide strip decodes well under imgutil.MaxPixels but is 400 times bigger scaled to the meme width
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 500))
+	for name, draw := range map[string]func() (*image.NRGBA, error){
+		"Retromeme":    func() (*image.NRGBA, error) { return local.Retromeme(img, "top", "bottom") },
+		"Motivational": func() (*image.NRGBA, error) { return local.Motivational(img, "top", "bottom") },
+		"Modernmeme":   func() (*image.NRGBA, error) { return local.Modernmeme(img, "text") },
+	} {
+		if _, err := draw(); !errors.Is(err, imgutil.ErrTooLarge) {
+			t.Errorf("%s() of a 1x500 image = %v, want ErrTooLarge", name, err)
+		}
+	}
+
+	if b := meme(t)(local.Retromeme(image.NewNRGBA(image.Rect(0, 0, 10, 500)), "", "")).Bounds(); b.Dx() != 400 || b.Dy() != 20000 {
+		t.Errorf("Retromeme() of a 10x500 image is %v, want it scaled to 400x20000", b)
+	}
+}
+
+func TestRendererMemes(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	renderer := testRenderer()
+	params := url.Values{"url": {server.URL + "/assets/input.png"}, "top_text": {"top"}, "bottom_text": {"bottom"}, "text": {"text"}}
+	for _, effect := range []dagpi.Effect{dagpi.EffectRetromeme, dagpi.EffectMotivational, dagpi.EffectModernmeme} {
+		out, err := renderer.Render(context.Background(), effect, params)
+		if err != nil {
+			t.Fatalf("Render(%s) = %v", effect, err)
+		}
+		if _, err = png.Decode(bytes.NewReader(out)); err != nil {
+			t.Errorf("Render(%s) didn't return a png: %v", effect, err)
+		}
+	}
+}
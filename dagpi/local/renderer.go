@@ -0,0 +1,288 @@
+// Package local renders dagpi effects on this machine with the standard library image packages.
+// It is not pixel identical to the API but close enough to stand in for it when the API is unavailable.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// ErrUnsupported is returned for effects that can't be rendered locally
+var ErrUnsupported = errors.New("effect is not supported by the local renderer")
+
+// ErrEmptyImage is returned for input images without a single pixel
+var ErrEmptyImage = errors.New("image has no pixels")
+
+// Renderer applies effects locally. It has the same effect methods as dagpi.Client so either can be used
+// by the same code. The zero value is ready to use.
+type Renderer struct {
+	// HTTPClient fetches input images, defaults to the Preflight's client which refuses private addresses
+	HTTPClient *http.Client
+
+	// Preflight validates input urls before they are fetched. It defaults to a dagpi.Preflight with its defaults,
+	// so urls pointing at private, loopback and link-local hosts are refused unless one with AllowPrivate is set.
+	Preflight *dagpi.Preflight
+
+	// MaxBytes is the largest input image fetched, defaults to dagpi.DefaultPreflightMaxBytes
+	MaxBytes int64
+}
+
+var effects = map[dagpi.Effect]func(image.Image) image.Image{
+	dagpi.EffectInvert:    func(img image.Image) image.Image { return Invert(img) },
+	dagpi.EffectMirror:    func(img image.Image) image.Image { return Mirror(img) },
+	dagpi.EffectFlipImage: func(img image.Image) image.Image { return FlipImage(img) },
+	dagpi.EffectPixelate:  func(img image.Image) image.Image { return Pixelate(img, 0) },
+	dagpi.EffectBlur:      func(img image.Image) image.Image { return Blur(img, 0) },
+	dagpi.EffectSepia:     func(img image.Image) image.Image { return Sepia(img) },
+	dagpi.EffectSobel:     func(img image.Image) image.Image { return Sobel(img) },
+	dagpi.EffectPosterize: func(img image.Image) image.Image { return Posterize(img, 0) },
+	dagpi.EffectCharcoal:  func(img image.Image) image.Image { return Charcoal(img) },
+	dagpi.EffectAscii:     asciiImage,
+}
+
+// animated effects are rendered as gifs
+var animations = map[dagpi.Effect]AnimationOptions{
+	dagpi.EffectSpinImage: {Animation: AnimationRotate, Frames: 24, Delay: 4},
+	dagpi.EffectShake:     {Animation: AnimationShake, Frames: 12, Delay: 3},
+	dagpi.EffectTriggered: {Animation: AnimationShake, Frames: 12, Delay: 3, Tint: color.NRGBA{R: 255, A: 80}},
+	dagpi.EffectRain:      {Animation: AnimationRain, Frames: 12, Delay: 6},
+}
+
+// routes that take more params than an image url
+var memes = map[dagpi.Effect]func(ctx context.Context, r *Renderer, params url.Values) ([]byte, error){
+	dagpi.EffectRetromeme: func(ctx context.Context, r *Renderer, params url.Values) ([]byte, error) {
+		return r.retromeme(ctx, params.Get("url"), params.Get("top_text"), params.Get("bottom_text"))
+	},
+	dagpi.EffectMotivational: func(ctx context.Context, r *Renderer, params url.Values) ([]byte, error) {
+		return r.motivational(ctx, params.Get("url"), params.Get("top_text"), params.Get("bottom_text"))
+	},
+	dagpi.EffectModernmeme: func(ctx context.Context, r *Renderer, params url.Values) ([]byte, error) {
+		return r.modernmeme(ctx, params.Get("url"), params.Get("text"))
+	},
+}
+
+var _ dagpi.Renderer = (*Renderer)(nil)
+
+// Supports reports whether effect can be rendered locally
+func (r *Renderer) Supports(effect dagpi.Effect) bool {
+	_, still := effects[effect]
+	_, animated := animations[effect]
+	_, meme := memes[effect]
+
+	return still || animated || meme
+}
+
+// Render renders a route with the params the API would have been called with, which lets
+// the Renderer be a dagpi.FallbackPolicy renderer. ctx cancels fetching the input image.
+func (r *Renderer) Render(ctx context.Context, effect dagpi.Effect, params url.Values) ([]byte, error) {
+	if meme, ok := memes[effect]; ok {
+		return meme(ctx, r, params)
+	}
+
+	return r.ApplyContext(ctx, effect, params.Get("url"))
+}
+
+// Apply fetches the image at url and renders a single image effect on it
+func (r *Renderer) Apply(effect dagpi.Effect, url string) ([]byte, error) {
+	return r.ApplyContext(context.Background(), effect, url)
+}
+
+// ApplyContext is Apply with a context that can cancel fetching the image
+func (r *Renderer) ApplyContext(ctx context.Context, effect dagpi.Effect, url string) ([]byte, error) {
+	_, still := effects[effect]
+	_, animated := animations[effect]
+	if !still && !animated {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, effect)
+	}
+
+	input, err := r.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ApplyBytes(effect, input)
+}
+
+// ApplyBytes renders effect on an already downloaded png, jpeg or gif.
+// Still effects return a png and animated ones a gif.
+func (r *Renderer) ApplyBytes(effect dagpi.Effect, input []byte) ([]byte, error) {
+	img, err := decode(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts, ok := animations[effect]; ok {
+		return EncodeGIF(Animate(img, opts))
+	}
+	if render, ok := effects[effect]; ok {
+		return imgutil.EncodePNG(render(img))
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupported, effect)
+}
+
+// renderFetched fetches the image at url and encodes the result of render as a png
+func (r *Renderer) renderFetched(ctx context.Context, url string, render func(image.Image) (image.Image, error)) ([]byte, error) {
+	img, err := r.fetchImage(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := render(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return imgutil.EncodePNG(rendered)
+}
+
+// decode decodes an input image, refusing empty ones so no effect has to deal with them
+func decode(input []byte) (image.Image, error) {
+	img, _, err := imgutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	return img, nil
+}
+
+// fetchImage downloads and decodes an input image
+func (r *Renderer) fetchImage(ctx context.Context, url string) (image.Image, error) {
+	input, err := r.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(input)
+}
+
+// fetch downloads an input image, refusing anything over MaxBytes
+func (r *Renderer) fetch(ctx context.Context, url string) ([]byte, error) {
+	// urls often come straight from users through the fallback, so they are checked the same way the API would be
+	preflight := r.Preflight
+	if preflight == nil {
+		preflight = &dagpi.Preflight{}
+	}
+	err := preflight.CheckContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = preflight.HTTPClient()
+	}
+	maxBytes := r.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = dagpi.DefaultPreflightMaxBytes
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetching %s: status %d", url, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("fetching %s: image is larger than %d bytes", url, maxBytes)
+	}
+
+	return body, nil
+}
+
+//region Effects
+
+// Invert Allows you to get an image with an inverted color effect.
+func (r *Renderer) Invert(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectInvert, url)
+}
+
+// Mirror an image along the y-axis
+func (r *Renderer) Mirror(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectMirror, url)
+}
+
+// FlipImage flip an image
+func (r *Renderer) FlipImage(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectFlipImage, url)
+}
+
+// Pixelate Allows you to pixelate an image.
+func (r *Renderer) Pixelate(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectPixelate, url)
+}
+
+// Blur Blurs a given image.
+func (r *Renderer) Blur(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectBlur, url)
+}
+
+// Sepia Tone an image.
+func (r *Renderer) Sepia(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectSepia, url)
+}
+
+// Sobel Allows you to get an image with the sobel effect.
+func (r *Renderer) Sobel(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectSobel, url)
+}
+
+// Posterize Posterizes an image.
+func (r *Renderer) Posterize(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectPosterize, url)
+}
+
+// Charcoal Image into a charcoal drawing.
+func (r *Renderer) Charcoal(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectCharcoal, url)
+}
+
+// SpinImage You spin me right round baby.
+func (r *Renderer) SpinImage(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectSpinImage, url)
+}
+
+// Shake a gif by having it wiggle.
+func (r *Renderer) Shake(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectShake, url)
+}
+
+// Triggered Allows you to get a triggered gif.
+func (r *Renderer) Triggered(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectTriggered, url)
+}
+
+// Rain Rain falling over an image.
+func (r *Renderer) Rain(url string) ([]byte, error) {
+	return r.Apply(dagpi.EffectRain, url)
+}
+
+//endregion
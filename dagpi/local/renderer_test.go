@@ -0,0 +1,222 @@
+package local_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// gradient is a small image with a different color in every pixel
+func gradient(w int, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 255 / w), G: uint8(y * 255 / h), B: uint8((x + y) % 256), A: 255})
+		}
+	}
+
+	return img
+}
+
+// testRenderer fetches from server, which is on loopback so private hosts have to be allowed
+func testRenderer() *local.Renderer {
+	return &local.Renderer{Preflight: &dagpi.Preflight{AllowPrivate: true}}
+}
+
+func TestEffectsKeepSize(t *testing.T) {
+	img := gradient(40, 30)
+	effects := map[string]*image.NRGBA{
+		"Invert":    local.Invert(img),
+		"Mirror":    local.Mirror(img),
+		"FlipImage": local.FlipImage(img),
+		"Pixelate":  local.Pixelate(img, 0),
+		"Blur":      local.Blur(img, 0),
+		"Sepia":     local.Sepia(img),
+		"Sobel":     local.Sobel(img),
+		"Posterize": local.Posterize(img, 0),
+		"Charcoal":  local.Charcoal(img),
+	}
+	for name, out := range effects {
+		if out.Bounds() != img.Bounds() {
+			t.Errorf("%s changed the bounds to %v", name, out.Bounds())
+		}
+	}
+}
+
+func TestEffectsUndo(t *testing.T) {
+	img := gradient(40, 30)
+	for name, twice := range map[string]*image.NRGBA{
+		"Invert":    local.Invert(local.Invert(img)),
+		"Mirror":    local.Mirror(local.Mirror(img)),
+		"FlipImage": local.FlipImage(local.FlipImage(img)),
+	} {
+		if !bytes.Equal(twice.Pix, img.Pix) {
+			t.Errorf("%s applied twice isn't the original image", name)
+		}
+	}
+
+	if local.Mirror(img).NRGBAAt(0, 0) != img.NRGBAAt(39, 0) {
+		t.Error("Mirror didn't swap the left and right edges")
+	}
+	if local.FlipImage(img).NRGBAAt(0, 0) != img.NRGBAAt(0, 29) {
+		t.Error("FlipImage didn't swap the top and bottom edges")
+	}
+}
+
+func TestPosterize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 100, B: 30, A: 200})
+
+	for levels, want := range map[int]color.NRGBA{
+		0:   {R: 170, G: 85, B: 0, A: 200},
+		2:   {R: 255, G: 0, B: 0, A: 200},
+		3:   {R: 255, G: 128, B: 0, A: 200},
+		256: {R: 200, G: 100, B: 30, A: 200},
+		300: {R: 200, G: 100, B: 30, A: 200},
+	} {
+		if got := local.Posterize(img, levels).NRGBAAt(0, 0); got != want {
+			t.Errorf("Posterize(%d) = %v, want %v", levels, got, want)
+		}
+	}
+}
+
+func TestRendererApply(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	renderer := testRenderer()
+	input := server.URL + "/assets/input.png"
+	for _, effect := range []dagpi.Effect{dagpi.EffectInvert, dagpi.EffectSepia, dagpi.EffectPixelate, dagpi.EffectAscii} {
+		out, err := renderer.Apply(effect, input)
+		if err != nil {
+			t.Fatalf("Apply(%s) = %v", effect, err)
+		}
+		if _, err = png.Decode(bytes.NewReader(out)); err != nil {
+			t.Errorf("Apply(%s) didn't return a png: %v", effect, err)
+		}
+	}
+
+	for _, effect := range []dagpi.Effect{dagpi.EffectSpinImage, dagpi.EffectTriggered} {
+		out, err := renderer.Apply(effect, input)
+		if err != nil {
+			t.Fatalf("Apply(%s) = %v", effect, err)
+		}
+		anim, err := gif.DecodeAll(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("Apply(%s) didn't return a gif: %v", effect, err)
+		}
+		if len(anim.Image) < 2 {
+			t.Errorf("Apply(%s) has %d frames, want an animation", effect, len(anim.Image))
+		}
+	}
+
+	if _, err := renderer.Apply(dagpi.EffectWanted, input); !errors.Is(err, local.ErrUnsupported) {
+		t.Errorf("Apply(wanted) = %v, want ErrUnsupported", err)
+	}
+	if renderer.Supports(dagpi.EffectWanted) || !renderer.Supports(dagpi.EffectRetromeme) {
+		t.Error("Supports() is wrong about wanted or retromeme")
+	}
+}
+
+func TestRendererRefusesPrivateHosts(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	renderer := &local.Renderer{}
+	_, err := renderer.Invert(server.URL + "/assets/input.png")
+	var inputErr *dagpi.InputError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("Invert() of a loopback url = %v, want an InputError", err)
+	}
+}
+
+// bomb is a valid png header claiming far more pixels than its few bytes hold
+func bomb(w uint32, h uint32) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()
+
+	// the IHDR chunk starts after the 8 byte signature, its data follows the length and type
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	return data
+}
+
+func TestRendererRefusesHugeImages(t *testing.T) {
+	data := bomb(100000, 100000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	if _, err := testRenderer().Invert(server.URL); !errors.Is(err, imgutil.ErrTooLarge) {
+		t.Errorf("Invert() of a 100000x100000 png = %v, want ErrTooLarge", err)
+	}
+	if _, err := testRenderer().ApplyBytes(dagpi.EffectInvert, data); !errors.Is(err, imgutil.ErrTooLarge) {
+		t.Errorf("ApplyBytes() of a 100000x100000 png = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestRendererMaxBytes(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	renderer := testRenderer()
+	renderer.MaxBytes = 10
+	if _, err := renderer.Invert(server.URL + "/assets/input.png"); err == nil {
+		t.Error("Invert() of an image over MaxBytes = nil, want an error")
+	}
+}
+
+func TestRenderStopsOnCancel(t *testing.T) {
+	// the headers arrive but the body never does
+	fetching := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Length", "1000")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == "GET" {
+			w.(http.Flusher).Flush()
+			fetching <- struct{}{}
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := testRenderer().Render(ctx, dagpi.EffectInvert, url.Values{"url": {server.URL + "/slow.png"}})
+		done <- err
+	}()
+
+	<-fetching
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Render() = %v, want context.Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Render() kept reading the body after the context was cancelled")
+	}
+}
@@ -0,0 +1,173 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// SilhouetteOptions configure Silhouette, the zero value gives a black silhouette on a transparent background
+type SilhouetteOptions struct {
+	// Fill is the color of the silhouette, defaults to black
+	Fill color.NRGBA
+
+	// Background behind the silhouette and the reveal, transparent by default
+	Background color.NRGBA
+
+	// Tolerance is how far a color may be from the background color to still count as background,
+	// used for images without transparency. Defaults to 40.
+	Tolerance int
+}
+
+func (o SilhouetteOptions) withDefaults() SilhouetteOptions {
+	if o.Fill == (color.NRGBA{}) {
+		o.Fill = color.NRGBA{A: 255}
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = 40
+	}
+
+	return o
+}
+
+// ForegroundMask finds the subject of an image. Images with transparency use their alpha channel, for the rest
+// the background is flood filled in from the edges starting with the colors close to the corners.
+func ForegroundMask(img image.Image, tolerance int) *image.Alpha {
+	src := imgutil.NRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	mask := image.NewAlpha(src.Bounds())
+
+	transparent := false
+	for i := 3; i < len(src.Pix); i += 4 {
+		if src.Pix[i] < 128 {
+			transparent = true
+			break
+		}
+	}
+	if transparent {
+		for i := 0; i < w*h; i++ {
+			if src.Pix[i*4+3] >= 128 {
+				mask.Pix[i] = 255
+			}
+		}
+
+		return mask
+	}
+
+	if tolerance <= 0 {
+		tolerance = 40
+	}
+
+	var r, g, b int
+	for _, p := range []image.Point{{0, 0}, {w - 1, 0}, {0, h - 1}, {w - 1, h - 1}} {
+		c := src.NRGBAAt(p.X, p.Y)
+		r, g, b = r+int(c.R), g+int(c.G), b+int(c.B)
+	}
+	background := color.NRGBA{R: uint8(r / 4), G: uint8(g / 4), B: uint8(b / 4)}
+	isBackground := func(x int, y int) bool {
+		c := src.NRGBAAt(x, y)
+		return abs(int(c.R)-int(background.R))+abs(int(c.G)-int(background.G))+abs(int(c.B)-int(background.B)) <= tolerance*3
+	}
+
+	// everything starts as foreground and the flood fill clears the background
+	for i := range mask.Pix {
+		mask.Pix[i] = 255
+	}
+
+	var stack []image.Point
+	push := func(x int, y int) {
+		if x >= 0 && x < w && y >= 0 && y < h && mask.Pix[y*w+x] == 255 && isBackground(x, y) {
+			mask.Pix[y*w+x] = 0
+			stack = append(stack, image.Point{X: x, Y: y})
+		}
+	}
+	for x := 0; x < w; x++ {
+		push(x, 0)
+		push(x, h-1)
+	}
+	for y := 0; y < h; y++ {
+		push(0, y)
+		push(w-1, y)
+	}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		push(p.X+1, p.Y)
+		push(p.X-1, p.Y)
+		push(p.X, p.Y+1)
+		push(p.X, p.Y-1)
+	}
+
+	return mask
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+// Silhouette fills the subject of an image with a single color, the question image of a who's that quiz
+func Silhouette(img image.Image, opts SilhouetteOptions) *image.NRGBA {
+	opts = opts.withDefaults()
+	mask := ForegroundMask(img, opts.Tolerance)
+
+	dst := image.NewNRGBA(mask.Bounds())
+	for i, a := range mask.Pix {
+		c := opts.Background
+		if a > 0 {
+			c = opts.Fill
+		}
+		dst.Pix[i*4], dst.Pix[i*4+1], dst.Pix[i*4+2], dst.Pix[i*4+3] = c.R, c.G, c.B, c.A
+	}
+
+	return dst
+}
+
+// Reveal is the answer image to a Silhouette, the subject as it is on the same background
+func Reveal(img image.Image, opts SilhouetteOptions) *image.NRGBA {
+	opts = opts.withDefaults()
+	mask := ForegroundMask(img, opts.Tolerance)
+	src := imgutil.NRGBA(img)
+
+	dst := image.NewNRGBA(mask.Bounds())
+	for i, a := range mask.Pix {
+		c := opts.Background
+		if a > 0 {
+			c = src.NRGBAAt(i%src.Bounds().Dx(), i/src.Bounds().Dx())
+		}
+		dst.Pix[i*4], dst.Pix[i*4+1], dst.Pix[i*4+2], dst.Pix[i*4+3] = c.R, c.G, c.B, c.A
+	}
+
+	return dst
+}
+
+// WTP fetches the image at url and makes a who's that quiz out of it. It returns the same shape as
+// dagpi.Client.WTP, with the silhouette as "question" and the reveal as "answer" data urls and
+// name as the only field of "Data".
+func (r *Renderer) WTP(url string, name string, opts SilhouetteOptions) (interface{}, error) {
+	img, err := r.fetchImage(context.Background(), url)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := imgutil.EncodePNG(Silhouette(img, opts))
+	if err != nil {
+		return nil, err
+	}
+	answer, err := imgutil.EncodePNG(Reveal(img, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"Data":     map[string]interface{}{"name": name},
+		"question": "data:image/png;base64," + base64.StdEncoding.EncodeToString(question),
+		"answer":   "data:image/png;base64," + base64.StdEncoding.EncodeToString(answer),
+	}, nil
+}
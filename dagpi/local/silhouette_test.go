@@ -0,0 +1,92 @@
+package local_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+)
+
+// subject is a red square in the middle of a plain background
+func subject(background color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 30, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			c := background
+			if x >= 10 && x < 20 && y >= 10 && y < 20 {
+				c = color.NRGBA{R: 200, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func checkMask(t *testing.T, mask *image.Alpha) {
+	t.Helper()
+
+	if mask.AlphaAt(15, 15).A == 0 {
+		t.Error("the middle of the subject isn't in the mask")
+	}
+	if mask.AlphaAt(0, 0).A != 0 || mask.AlphaAt(25, 5).A != 0 {
+		t.Error("the background is in the mask")
+	}
+}
+
+func TestForegroundMask(t *testing.T) {
+	t.Run("transparent", func(t *testing.T) {
+		checkMask(t, local.ForegroundMask(subject(color.NRGBA{}), 0))
+	})
+	t.Run("solid background", func(t *testing.T) {
+		checkMask(t, local.ForegroundMask(subject(color.NRGBA{R: 240, G: 240, B: 240, A: 255}), 0))
+	})
+}
+
+func TestSilhouetteAndReveal(t *testing.T) {
+	img := subject(color.NRGBA{R: 240, G: 240, B: 240, A: 255})
+	fill := color.NRGBA{B: 255, A: 255}
+
+	question := local.Silhouette(img, local.SilhouetteOptions{Fill: fill})
+	if question.NRGBAAt(15, 15) != fill {
+		t.Errorf("the subject is %v, want the fill color", question.NRGBAAt(15, 15))
+	}
+	if question.NRGBAAt(0, 0) != (color.NRGBA{}) {
+		t.Errorf("the background is %v, want transparent", question.NRGBAAt(0, 0))
+	}
+
+	answer := local.Reveal(img, local.SilhouetteOptions{})
+	if answer.NRGBAAt(15, 15) != img.NRGBAAt(15, 15) {
+		t.Errorf("the revealed subject is %v, want %v", answer.NRGBAAt(15, 15), img.NRGBAAt(15, 15))
+	}
+	if answer.NRGBAAt(0, 0) != (color.NRGBA{}) {
+		t.Errorf("the revealed background is %v, want transparent", answer.NRGBAAt(0, 0))
+	}
+}
+
+func TestRendererWTP(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	remote, err := server.Client().WTP()
+	if err != nil {
+		t.Fatalf("WTP() = %v", err)
+	}
+	fields := remote.(map[string]interface{})
+
+	data, err := testRenderer().WTP(fields["answer"].(string), "Bulbasaur", local.SilhouetteOptions{})
+	if err != nil {
+		t.Fatalf("local WTP() = %v", err)
+	}
+	quiz := data.(map[string]interface{})
+	for key := range fields {
+		if _, ok := quiz[key]; !ok {
+			t.Errorf("the local quiz has no %q like the API's", key)
+		}
+	}
+	if name := quiz["Data"].(map[string]interface{})["name"]; name != "Bulbasaur" {
+		t.Errorf("Data.name = %v, want Bulbasaur", name)
+	}
+}
@@ -0,0 +1,156 @@
+package local
+
+import (
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"sync"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// TyperacerOptions configure a TyperacerRenderer, the zero value gives white text on a dark 600 pixel wide image
+type TyperacerOptions struct {
+	// Sentences Typeracer picks from
+	Sentences []string
+
+	// Scale is the font size as a multiple of the 7 pixel font, defaults to 3
+	Scale int
+
+	// Width of the image, sentences wrap to fit. Defaults to 600.
+	Width int
+
+	// Background and Text colors, default to dark gray and white
+	Background color.NRGBA
+	Text       color.NRGBA
+
+	// Noise is how much noise is drawn over the text to throw off OCR, from 0 to 1 with anything higher
+	// treated as 1. Defaults to 0.2, -1 draws none.
+	Noise float64
+
+	// Seed makes the sentence picks and noise repeatable, zero seeds from Clock
+	Seed int64
+
+	// Clock seeds the renderer when Seed is zero, defaults to dagpi.SystemClock
+	Clock dagpi.Clock
+}
+
+// TyperacerRenderer renders sentences into typeracer images, it is safe for concurrent use
+type TyperacerRenderer struct {
+	opts TyperacerOptions
+	mu   sync.Mutex
+	rng  *rand.Rand
+}
+
+// NewTyperacerRenderer creates a TyperacerRenderer, see TyperacerOptions for the defaults
+func NewTyperacerRenderer(opts TyperacerOptions) *TyperacerRenderer {
+	if opts.Scale <= 0 {
+		opts.Scale = 3
+	}
+	if opts.Width <= 0 {
+		opts.Width = 600
+	}
+	if opts.Background == (color.NRGBA{}) {
+		opts.Background = color.NRGBA{R: 44, G: 47, B: 51, A: 255}
+	}
+	if opts.Text == (color.NRGBA{}) {
+		opts.Text = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	if opts.Noise == 0 {
+		opts.Noise = 0.2
+	}
+	if opts.Noise > 1 {
+		opts.Noise = 1
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = dagpi.SystemClock
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = opts.Clock.Now().UnixNano()
+	}
+
+	return &TyperacerRenderer{opts: opts, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Typeracer get a sentence on an image, picked from the configured sentences.
+// Returns the same shape as dagpi.Client.Typeracer with the image as a data url.
+func (t *TyperacerRenderer) Typeracer() (interface{}, error) {
+	if len(t.opts.Sentences) == 0 {
+		return nil, errors.New("typeracer renderer has no sentences")
+	}
+
+	t.mu.Lock()
+	sentence := t.opts.Sentences[t.rng.Intn(len(t.opts.Sentences))]
+	t.mu.Unlock()
+
+	buffer, err := t.Render(sentence)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"image":    "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer),
+		"sentence": sentence,
+	}, nil
+}
+
+// Render draws a sentence as a png
+func (t *TyperacerRenderer) Render(sentence string) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	scale := t.opts.Scale
+	w := t.opts.Width
+	margin := 4 * scale
+	lines := wrapText(sentence, scale, w-2*margin)
+	h := textHeight(len(lines), scale) + 2*margin
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(t.opts.Background), image.Point{}, draw.Src)
+
+	noise := t.opts.Noise
+	if noise < 0 {
+		noise = 0
+	}
+
+	for i, line := range lines {
+		y := margin + i*lineHeight*scale
+		// nudge characters off the baseline a little, people don't notice but OCR line finding does
+		for j, r := range []rune(line) {
+			dy := 0
+			if noise > 0 {
+				dy = t.rng.Intn(scale/2+1) - scale/4
+			}
+			drawText(img, margin+j*advance*scale, y+dy, string(r), scale, t.opts.Text)
+		}
+	}
+
+	t.addNoise(img, noise)
+
+	return imgutil.EncodePNG(img)
+}
+
+// addNoise draws faint lines and specks in the text color over the image, must hold t.mu
+func (t *TyperacerRenderer) addNoise(img *image.NRGBA, amount float64) {
+	if amount <= 0 {
+		return
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	faint := t.opts.Text
+	faint.A = uint8(40 + 60*amount)
+
+	for i := 0; i < int(amount*float64(w*h)/60); i++ {
+		fillRect(img, t.rng.Intn(w), t.rng.Intn(h), 1+t.rng.Intn(2), 1+t.rng.Intn(2), faint)
+	}
+	for i := 0; i < int(amount*20)+1; i++ {
+		drawLine(img, t.rng.Intn(w), t.rng.Intn(h), t.rng.Intn(w), t.rng.Intn(h), 1, faint)
+	}
+}
@@ -0,0 +1,117 @@
+package local_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/local"
+)
+
+func TestTyperacerRender(t *testing.T) {
+	renderer := local.NewTyperacerRenderer(local.TyperacerOptions{Width: 300, Seed: 1})
+	short, err := renderer.Render("short")
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	long, err := renderer.Render(strings.Repeat("a sentence long enough to wrap ", 4))
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+
+	shortImg, err := png.Decode(bytes.NewReader(short))
+	if err != nil {
+		t.Fatalf("Render() didn't return a png: %v", err)
+	}
+	longImg, _ := png.Decode(bytes.NewReader(long))
+	if shortImg.Bounds().Dx() != 300 {
+		t.Errorf("image is %d wide, want 300", shortImg.Bounds().Dx())
+	}
+	if longImg.Bounds().Dy() <= shortImg.Bounds().Dy() {
+		t.Error("a long sentence didn't wrap onto more lines")
+	}
+}
+
+func TestTyperacerWithoutNoise(t *testing.T) {
+	background := color.NRGBA{R: 10, G: 20, B: 30, A: 255}
+	text := color.NRGBA{R: 250, G: 240, B: 230, A: 255}
+	out, err := local.NewTyperacerRenderer(local.TyperacerOptions{Background: background, Text: text, Noise: -1, Seed: 1}).Render("no noise")
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+
+	img, _ := png.Decode(bytes.NewReader(out))
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA); c != background && c != text {
+				t.Fatalf("pixel %d,%d is %v, want only the background and text colors without noise", x, y, c)
+			}
+		}
+	}
+}
+
+func TestTyperacerNoiseIsClamped(t *testing.T) {
+	render := func(noise float64) []byte {
+		out, err := local.NewTyperacerRenderer(local.TyperacerOptions{Noise: noise, Seed: 3}).Render("clamp the noise")
+		if err != nil {
+			t.Fatalf("Render() with noise %v = %v", noise, err)
+		}
+		return out
+	}
+
+	full := render(1)
+	for _, noise := range []float64{3.6, 10} {
+		if !bytes.Equal(render(noise), full) {
+			t.Errorf("noise %v rendered differently from 1", noise)
+		}
+	}
+}
+
+func TestTyperacer(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	remote, err := server.Client().Typeracer()
+	if err != nil {
+		t.Fatalf("Typeracer() = %v", err)
+	}
+
+	sentences := []string{"the quick brown fox", "jumps over the lazy dog"}
+	data, err := local.NewTyperacerRenderer(local.TyperacerOptions{Sentences: sentences, Seed: 1}).Typeracer()
+	if err != nil {
+		t.Fatalf("local Typeracer() = %v", err)
+	}
+	fields := data.(map[string]interface{})
+	for key := range remote.(map[string]interface{}) {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("the local typeracer has no %q like the API's", key)
+		}
+	}
+	if sentence := fields["sentence"]; sentence != sentences[0] && sentence != sentences[1] {
+		t.Errorf("sentence = %v, want one of the configured ones", sentence)
+	}
+
+	if _, err = local.NewTyperacerRenderer(local.TyperacerOptions{}).Typeracer(); err == nil {
+		t.Error("Typeracer() without sentences = nil, want an error")
+	}
+}
+
+func TestTyperacerSeedsFromClock(t *testing.T) {
+	render := func() []byte {
+		clock := dagpitest.NewClock(time.Time{})
+		out, err := local.NewTyperacerRenderer(local.TyperacerOptions{Clock: clock}).Render("seeded from the clock")
+		if err != nil {
+			t.Fatalf("Render() = %v", err)
+		}
+		return out
+	}
+
+	if !bytes.Equal(render(), render()) {
+		t.Error("two renderers seeded from a clock at the same time drew different noise")
+	}
+}
@@ -0,0 +1,116 @@
+// Package phash compares images by perceptual hash for golden tests. Renders of the same effect differ slightly
+// from run to run, so instead of comparing bytes the hashes of the result and the golden image have to be
+// within a Hamming distance of each other. The test helpers built on it are in phashtest.
+package phash
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/bits"
+
+	"github.com/beamer64/godagpi/internal/imgutil"
+)
+
+// DefaultMaxDistance is a Hamming distance that tolerates rendering noise but not a different image
+const DefaultMaxDistance = 8
+
+// Hash is a 64 bit perceptual hash
+type Hash uint64
+
+// Distance is the number of bits that differ between two hashes
+func (h Hash) Distance(other Hash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// gray flattens img onto white and shrinks it to a w x h grid of luma values
+func gray(img image.Image, w int, h int) []uint8 {
+	flat := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	small := imgutil.Resize(flat, w, h)
+	values := make([]uint8, 0, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			values = append(values, imgutil.Luma(small.NRGBAAt(x, y)))
+		}
+	}
+
+	return values
+}
+
+// AHash sets a bit for every cell of an 8x8 thumbnail brighter than the average
+func AHash(img image.Image) Hash {
+	values := gray(img, 8, 8)
+
+	total := 0
+	for _, v := range values {
+		total += int(v)
+	}
+	mean := total / len(values)
+
+	var hash Hash
+	for i, v := range values {
+		if int(v) > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash
+}
+
+// DHash sets a bit for every cell of a 9x8 thumbnail brighter than its right neighbour
+func DHash(img image.Image) Hash {
+	values := gray(img, 9, 8)
+
+	var hash Hash
+	bit := uint(0)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if values[y*9+x] > values[y*9+x+1] {
+				hash |= 1 << bit
+			}
+			bit++
+		}
+	}
+
+	return hash
+}
+
+// Distance is how far apart two images are by both hashes
+type Distance struct {
+	AHash int
+	DHash int
+}
+
+// Max is the larger of the two distances
+func (d Distance) Max() int {
+	if d.AHash > d.DHash {
+		return d.AHash
+	}
+
+	return d.DHash
+}
+
+// Compare decodes two pngs, jpegs or gifs and returns how far apart they are. Gifs are compared by their first frame.
+func Compare(got []byte, want []byte) (Distance, error) {
+	gotImg, _, err := imgutil.Decode(got)
+	if err != nil {
+		return Distance{}, fmt.Errorf("decoding result: %w", err)
+	}
+	wantImg, _, err := imgutil.Decode(want)
+	if err != nil {
+		return Distance{}, fmt.Errorf("decoding golden image: %w", err)
+	}
+
+	return Distance{
+		AHash: AHash(gotImg).Distance(AHash(wantImg)),
+		DHash: DHash(gotImg).Distance(DHash(wantImg)),
+	}, nil
+}
@@ -0,0 +1,72 @@
+package phash_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/phash"
+)
+
+// stripes is a diagonal stripe pattern, shifted moves every stripe along by a pixel
+func stripes(shifted bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			offset := 0
+			if shifted {
+				offset = 1
+			}
+			c := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+			if (x+y+offset)%32 < 12 {
+				c = color.NRGBA{R: 240, G: 220, B: 180, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestHashes(t *testing.T) {
+	img := stripes(false)
+	if d := phash.AHash(img).Distance(phash.AHash(img)); d != 0 {
+		t.Errorf("AHash distance to itself = %d, want 0", d)
+	}
+
+	shifted := stripes(true)
+	for name, hash := range map[string]func(image.Image) phash.Hash{"AHash": phash.AHash, "DHash": phash.DHash} {
+		if d := hash(img).Distance(hash(shifted)); d > phash.DefaultMaxDistance {
+			t.Errorf("%s distance to a one pixel shift = %d, want at most %d", name, d, phash.DefaultMaxDistance)
+		}
+	}
+
+	// a flipped image is a different image
+	flipped := image.NewNRGBA(img.Bounds())
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			flipped.SetNRGBA(63-x, y, img.NRGBAAt(x, y))
+		}
+	}
+	if d := phash.DHash(img).Distance(phash.DHash(flipped)); d <= phash.DefaultMaxDistance {
+		t.Errorf("DHash distance to the flipped image = %d, want more than %d", d, phash.DefaultMaxDistance)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	png := dagpitest.PlaceholderPNG("compare")
+	distance, err := phash.Compare(png, png)
+	if err != nil || distance.Max() != 0 {
+		t.Errorf("Compare() of the same png = %+v, %v, want 0", distance, err)
+	}
+
+	// gifs are compared by their first frame
+	if _, err = phash.Compare(dagpitest.PlaceholderGIF("compare"), png); err != nil {
+		t.Errorf("Compare() of a gif and a png = %v", err)
+	}
+
+	if _, err = phash.Compare([]byte("not an image"), png); err == nil {
+		t.Error("Compare() of a non image = nil, want an error")
+	}
+}
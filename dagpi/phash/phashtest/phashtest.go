@@ -0,0 +1,67 @@
+// Package phashtest has the test helpers for golden image tests built on phash.
+//
+// Run tests with DAGPI_UPDATE_GOLDEN=1 to rewrite the golden files with the current results.
+package phashtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/phash"
+)
+
+// UpdateEnv is the environment variable that turns on update mode
+const UpdateEnv = "DAGPI_UPDATE_GOLDEN"
+
+// Update makes AssertGolden rewrite golden files instead of comparing against them.
+// It is on when UpdateEnv is set.
+var Update = os.Getenv(UpdateEnv) != ""
+
+// AssertSimilar fails the test when got and want are further than maxDistance apart
+func AssertSimilar(t testing.TB, got []byte, want []byte, maxDistance int) {
+	t.Helper()
+
+	distance, err := phash.Compare(got, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if distance.Max() > maxDistance {
+		t.Errorf("images differ: aHash distance %d, dHash distance %d, max %d", distance.AHash, distance.DHash, maxDistance)
+	}
+}
+
+// AssertGolden compares got with the golden image at path, like "testdata/wanted.png".
+// In update mode the golden image is replaced with got instead.
+func AssertGolden(t testing.TB, got []byte, path string, maxDistance int) {
+	t.Helper()
+
+	if Update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, got, 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("updated golden image %s", path)
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden image %s does not exist, run with %s=1 to create it", path, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	distance, err := phash.Compare(got, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if distance.Max() > maxDistance {
+		t.Errorf("result differs from %s: aHash distance %d, dHash distance %d, max %d", path, distance.AHash, distance.DHash, maxDistance)
+	}
+}
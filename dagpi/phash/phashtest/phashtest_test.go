@@ -0,0 +1,79 @@
+package phashtest_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+	"github.com/beamer64/godagpi/dagpi/phash"
+	"github.com/beamer64/godagpi/dagpi/phash/phashtest"
+)
+
+// recorder is a testing.TB that records failures instead of failing the test. Fatal doesn't stop the
+// helper, so only the first failure counts.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprint(args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Logf(string, ...interface{}) {}
+
+func TestAssertSimilar(t *testing.T) {
+	a, b := dagpitest.PlaceholderPNG("a"), dagpitest.PlaceholderPNG("b")
+
+	same := &recorder{TB: t}
+	phashtest.AssertSimilar(same, a, a, 0)
+	if len(same.failures) != 0 {
+		t.Errorf("AssertSimilar() of the same image failed: %v", same.failures)
+	}
+
+	broken := &recorder{TB: t}
+	phashtest.AssertSimilar(broken, []byte("not an image"), a, phash.DefaultMaxDistance)
+	if len(broken.failures) == 0 || !strings.Contains(broken.failures[0], "decoding result") {
+		t.Errorf("AssertSimilar() of a non image failed with %v, want a decoding error", broken.failures)
+	}
+
+	distance, _ := phash.Compare(a, b)
+	different := &recorder{TB: t}
+	phashtest.AssertSimilar(different, a, b, distance.Max()-1)
+	if len(different.failures) != 1 {
+		t.Errorf("AssertSimilar() %d apart with a max of %d failed %d times, want once", distance.Max(), distance.Max()-1, len(different.failures))
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden", "image.png")
+	img := dagpitest.PlaceholderPNG("golden")
+
+	missing := &recorder{TB: t}
+	phashtest.AssertGolden(missing, img, path, 0)
+	if len(missing.failures) == 0 || !strings.Contains(missing.failures[0], phashtest.UpdateEnv) {
+		t.Fatalf("AssertGolden() without a golden image failed with %v, want a hint to create it", missing.failures)
+	}
+
+	phashtest.Update = true
+	phashtest.AssertGolden(t, img, path, 0)
+	phashtest.Update = false
+	if written, err := ioutil.ReadFile(path); err != nil || string(written) != string(img) {
+		t.Fatalf("update mode didn't write the golden image: %v", err)
+	}
+
+	phashtest.AssertGolden(t, img, path, 0)
+}
@@ -0,0 +1,133 @@
+package dagpi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Uploader hosts an image somewhere the API can fetch it from and returns its url.
+// Pipelines use it to hand the result of one effect to the next.
+type Uploader interface {
+	Upload(image []byte) (string, error)
+}
+
+// UploaderFunc lets a plain function be used as an Uploader
+type UploaderFunc func(image []byte) (string, error)
+
+// Upload calls f(image)
+func (f UploaderFunc) Upload(image []byte) (string, error) {
+	return f(image)
+}
+
+// Pipeline runs a sequence of effects, each one on the result of the last.
+// Build one with Client.Pipeline.
+type Pipeline struct {
+	client           *Client
+	effects          []Effect
+	keepIntermediate bool
+}
+
+// PipelineStep is the outcome of a single effect in a pipeline
+type PipelineStep struct {
+	Effect Effect
+	// Input is the url the effect was applied to
+	Input string
+	// Duration is how long the API took to apply the effect
+	Duration time.Duration
+	// UploadDuration is how long it took to upload the result for the next step, zero for the last step
+	UploadDuration time.Duration
+	// Image is only kept for the last step unless KeepIntermediate was set
+	Image []byte
+}
+
+// PipelineResult holds the final image and the steps it took to get there
+type PipelineResult struct {
+	Image    []byte
+	Steps    []PipelineStep
+	Duration time.Duration
+}
+
+// PipelineError is returned when a step fails, the steps that did complete are still in the result
+type PipelineError struct {
+	Step   int
+	Effect Effect
+	Err    error
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("pipeline step %d (%s) failed: %v", e.Step+1, e.Effect, e.Err)
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// Pipeline starts a pipeline of effects, e.g. client.Pipeline(dagpi.EffectPixelate, dagpi.EffectSepia).Then(dagpi.EffectWanted).
+// Every step but the last is uploaded with the client's Uploader, so one has to be set for pipelines with more than one effect.
+func (c *Client) Pipeline(effects ...Effect) *Pipeline {
+	return &Pipeline{client: c, effects: effects}
+}
+
+// Then adds an effect to the end of the pipeline
+func (p *Pipeline) Then(effect Effect) *Pipeline {
+	p.effects = append(p.effects, effect)
+	return p
+}
+
+// KeepIntermediate keeps the image of every step in the result instead of only the last one
+func (p *Pipeline) KeepIntermediate() *Pipeline {
+	p.keepIntermediate = true
+	return p
+}
+
+// Run applies every effect in order starting with the image at url.
+// It stops at the first failing step and returns the steps completed so far along with a *PipelineError.
+func (p *Pipeline) Run(url string) (*PipelineResult, error) {
+	if len(p.effects) == 0 {
+		return nil, errors.New("pipeline has no effects")
+	}
+	if len(p.effects) > 1 && p.client.Uploader == nil {
+		return nil, errors.New("pipelines with more than one effect need the client's Uploader to be set")
+	}
+
+	clock := p.client.clock()
+	start := clock.Now()
+	result := &PipelineResult{}
+	input := url
+
+	for i, effect := range p.effects {
+		step := PipelineStep{Effect: effect, Input: input}
+
+		stepStart := clock.Now()
+		image, err := p.client.Apply(effect, input)
+		step.Duration = clock.Now().Sub(stepStart)
+		if err != nil {
+			result.Duration = clock.Now().Sub(start)
+			return result, &PipelineError{Step: i, Effect: effect, Err: err}
+		}
+
+		last := i == len(p.effects)-1
+		if last || p.keepIntermediate {
+			step.Image = image
+		}
+		result.Image = image
+
+		if !last {
+			uploadStart := clock.Now()
+			input, err = p.client.Uploader.Upload(image)
+			step.UploadDuration = clock.Now().Sub(uploadStart)
+			if err != nil {
+				result.Steps = append(result.Steps, step)
+				result.Duration = clock.Now().Sub(start)
+				return result, &PipelineError{Step: i, Effect: effect, Err: fmt.Errorf("upload: %w", err)}
+			}
+		}
+
+		result.Steps = append(result.Steps, step)
+	}
+
+	result.Duration = clock.Now().Sub(start)
+
+	return result, nil
+}
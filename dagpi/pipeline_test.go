@@ -0,0 +1,116 @@
+package dagpi_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func TestPipelineRun(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	clock := dagpitest.NewClock(time.Time{})
+	uploads := 0
+	client := server.Client()
+	client.Clock = clock
+	client.Uploader = dagpi.UploaderFunc(func(image []byte) (string, error) {
+		uploads++
+		clock.Advance(time.Second)
+		return fmt.Sprintf("%s/assets/step-%d.png", server.URL, uploads), nil
+	})
+
+	result, err := client.Pipeline(dagpi.EffectPixelate, dagpi.EffectSepia).Then(dagpi.EffectWanted).Run(server.URL + "/assets/input.png")
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	if len(result.Steps) != 3 {
+		t.Fatalf("got %d steps, want 3", len(result.Steps))
+	}
+	if uploads != 2 {
+		t.Errorf("got %d uploads, want 2", uploads)
+	}
+	if result.Steps[1].Input != server.URL+"/assets/step-1.png" {
+		t.Errorf("step 2 input = %s, want the first upload", result.Steps[1].Input)
+	}
+	if result.Steps[0].Image != nil || result.Steps[2].Image == nil {
+		t.Error("only the last step should keep its image")
+	}
+	if result.Steps[0].UploadDuration != time.Second || result.Steps[2].UploadDuration != 0 {
+		t.Errorf("upload durations = %v and %v, want 1s and 0", result.Steps[0].UploadDuration, result.Steps[2].UploadDuration)
+	}
+	if result.Duration != 2*time.Second {
+		t.Errorf("Duration = %v, want 2s", result.Duration)
+	}
+	for _, path := range []string{"image/pixel", "image/sepia", "image/wanted"} {
+		if server.Requests(path) != 1 {
+			t.Errorf("%s got %d requests, want 1", path, server.Requests(path))
+		}
+	}
+}
+
+func TestPipelineKeepIntermediate(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	client := server.Client()
+	client.Uploader = dagpi.UploaderFunc(func(image []byte) (string, error) {
+		return server.URL + "/assets/step.png", nil
+	})
+
+	result, err := client.Pipeline(dagpi.EffectPixelate, dagpi.EffectSepia).KeepIntermediate().Run(server.URL + "/assets/input.png")
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	for i, step := range result.Steps {
+		if step.Image == nil {
+			t.Errorf("step %d has no image", i+1)
+		}
+	}
+}
+
+func TestPipelineStepError(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	server.Fail("image/sepia", dagpitest.Fault{Status: http.StatusInternalServerError, Message: "broken"})
+
+	client := server.Client()
+	client.Uploader = dagpi.UploaderFunc(func(image []byte) (string, error) {
+		return server.URL + "/assets/step.png", nil
+	})
+
+	result, err := client.Pipeline(dagpi.EffectPixelate, dagpi.EffectSepia, dagpi.EffectWanted).Run(server.URL + "/assets/input.png")
+	var pipelineErr *dagpi.PipelineError
+	if !errors.As(err, &pipelineErr) {
+		t.Fatalf("Run() = %v, want a PipelineError", err)
+	}
+	if pipelineErr.Step != 1 || pipelineErr.Effect != dagpi.EffectSepia {
+		t.Errorf("failed at step %d (%s), want 1 (sepia)", pipelineErr.Step, pipelineErr.Effect)
+	}
+	var apiErr *dagpi.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Run() = %v, want it to wrap the 500", err)
+	}
+	if len(result.Steps) != 1 {
+		t.Errorf("got %d completed steps, want 1", len(result.Steps))
+	}
+	if server.Requests("image/wanted") != 0 {
+		t.Error("the pipeline kept going after the failed step")
+	}
+}
+
+func TestPipelineNeedsUploader(t *testing.T) {
+	client := &dagpi.Client{}
+	if _, err := client.Pipeline(dagpi.EffectPixelate, dagpi.EffectSepia).Run("https://example.com/a.png"); err == nil {
+		t.Error("Run() without an Uploader = nil, want an error")
+	}
+	if _, err := client.Pipeline().Run("https://example.com/a.png"); err == nil {
+		t.Error("Run() without effects = nil, want an error")
+	}
+}
@@ -0,0 +1,351 @@
+package dagpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultPreflightMaxBytes is the largest image a Preflight accepts when MaxBytes is not set
+const DefaultPreflightMaxBytes = 8 << 20
+
+// Preflight checks user supplied image urls before they are handed to the API.
+// Set Client.Preflight to enable it, the zero value uses sane defaults.
+type Preflight struct {
+	// Schemes allowed for image urls, defaults to http and https
+	Schemes []string
+
+	// MaxBytes is the largest image accepted, defaults to DefaultPreflightMaxBytes
+	MaxBytes int64
+
+	// AllowPrivate lets urls point at private, loopback and link-local hosts
+	AllowPrivate bool
+
+	// Timeout for the whole check, defaults to 10 seconds
+	Timeout time.Duration
+
+	// Clock times the check out, defaults to SystemClock
+	Clock Clock
+}
+
+// InputError is returned when a user supplied image url fails a preflight check
+type InputError struct {
+	URL    string
+	Reason string
+	Err    error
+}
+
+func (e *InputError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid image url '%s': %s: %v", e.URL, e.Reason, e.Err)
+	}
+
+	return fmt.Sprintf("invalid image url '%s': %s", e.URL, e.Reason)
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// ranges that are never reachable from the API and should not be probed by us either
+var blockedNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"127.0.0.0/8",
+		"169.254.0.0/16",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"::1/128",
+		"fc00::/7",
+		"fe80::/10",
+	}
+
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}()
+
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Preflight) schemes() []string {
+	if len(p.Schemes) == 0 {
+		return []string{"http", "https"}
+	}
+
+	return p.Schemes
+}
+
+func (p *Preflight) maxBytes() int64 {
+	if p.MaxBytes <= 0 {
+		return DefaultPreflightMaxBytes
+	}
+
+	return p.MaxBytes
+}
+
+func (p *Preflight) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return 10 * time.Second
+	}
+
+	return p.Timeout
+}
+
+// Check validates a single image url. It checks the scheme, refuses hosts that resolve to
+// private addresses and probes the url to confirm it is an image under the size limit.
+func (p *Preflight) Check(rawURL string) error {
+	return p.CheckContext(context.Background(), rawURL)
+}
+
+// CheckContext is Check with a context that can cancel the check
+func (p *Preflight) CheckContext(ctx context.Context, rawURL string) error {
+	ctx, cancel := withTimeout(ctx, p.Clock, p.timeout())
+	defer cancel()
+
+	return p.check(ctx, rawURL)
+}
+
+func (p *Preflight) check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &InputError{URL: rawURL, Reason: "malformed url", Err: err}
+	}
+	if err = p.checkScheme(u); err != nil {
+		return &InputError{URL: rawURL, Reason: err.Error()}
+	}
+	if u.Hostname() == "" {
+		return &InputError{URL: rawURL, Reason: "missing host"}
+	}
+
+	if !p.AllowPrivate {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+		if err != nil {
+			return &InputError{URL: rawURL, Reason: "host does not resolve", Err: err}
+		}
+		for _, addr := range addrs {
+			if isBlockedIP(addr.IP) {
+				return &InputError{URL: rawURL, Reason: "host resolves to a private address"}
+			}
+		}
+	}
+
+	contentType, size, err := p.probe(ctx, u)
+	if err != nil {
+		if inputErr, ok := err.(*InputError); ok {
+			inputErr.URL = rawURL
+			return inputErr
+		}
+
+		return &InputError{URL: rawURL, Reason: "url could not be fetched", Err: err}
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return &InputError{URL: rawURL, Reason: fmt.Sprintf("content type '%s' is not an image", contentType)}
+	}
+	if size > p.maxBytes() {
+		return &InputError{URL: rawURL, Reason: fmt.Sprintf("image is %d bytes, the limit is %d", size, p.maxBytes())}
+	}
+
+	return nil
+}
+
+func (p *Preflight) checkScheme(u *url.URL) error {
+	for _, scheme := range p.schemes() {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("scheme '%s' is not allowed", u.Scheme)
+}
+
+// HTTPClient returns a client that refuses to dial the addresses the preflight refuses, so redirects and dns
+// rebinding can't sneak past the lookup in Check. Use it to download urls that passed the check.
+func (p *Preflight) HTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Control: func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && !p.AllowPrivate && isBlockedIP(ip) {
+				return &InputError{Reason: "host resolves to a private address"}
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   p.timeout(),
+			ResponseHeaderTimeout: p.timeout(),
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return &InputError{Reason: "too many redirects"}
+			}
+
+			return p.checkScheme(req.URL)
+		},
+	}
+}
+
+// probe asks for the headers first and falls back to fetching the first few bytes
+// for servers that don't answer HEAD or don't send a content type
+func (p *Preflight) probe(ctx context.Context, u *url.URL) (string, int64, error) {
+	httpClient := p.HTTPClient()
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", u.String(), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", 0, unwrapInputError(err)
+	}
+	_ = resp.Body.Close()
+
+	// chunked responses have no Content-Length, the size has to come from a GET instead
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 && contentType != "" && resp.ContentLength >= 0 {
+		return contentType, resp.ContentLength, nil
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Range", "bytes=0-511")
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		return "", 0, unwrapInputError(err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", 0, &InputError{Reason: fmt.Sprintf("url responded with status %d", resp.StatusCode)}
+	}
+
+	head, err := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	if err != nil {
+		return "", 0, err
+	}
+
+	contentType = http.DetectContentType(head)
+	if resp.StatusCode != http.StatusPartialContent {
+		// the server ignored the range so the body is the whole image, count it when the length isn't sent
+		size := resp.ContentLength
+		if size < 0 {
+			size, err = countBytes(resp.Body, int64(len(head)), p.maxBytes())
+		}
+
+		return contentType, size, err
+	}
+
+	// Content-Range: bytes 0-511/12345, the total can be * when the server doesn't know it
+	if i := strings.LastIndex(resp.Header.Get("Content-Range"), "/"); i >= 0 {
+		if total, err := strconv.ParseInt(resp.Header.Get("Content-Range")[i+1:], 10, 64); err == nil {
+			return contentType, total, nil
+		}
+	}
+
+	size, err := p.measure(ctx, httpClient, u)
+
+	return contentType, size, err
+}
+
+// measure downloads the image to find its size, it stops reading once the size is over the limit
+func (p *Preflight) measure(ctx context.Context, httpClient *http.Client, u *url.URL) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, unwrapInputError(err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, &InputError{Reason: fmt.Sprintf("url responded with status %d", resp.StatusCode)}
+	}
+
+	return countBytes(resp.Body, 0, p.maxBytes())
+}
+
+// countBytes reads at most one byte past maxBytes so a size over the limit is reported without reading it all
+func countBytes(body io.Reader, read int64, maxBytes int64) (int64, error) {
+	if read > maxBytes {
+		return read, nil
+	}
+	n, err := io.Copy(ioutil.Discard, io.LimitReader(body, maxBytes+1-read))
+
+	return read + n, err
+}
+
+func unwrapInputError(err error) error {
+	var inputErr *InputError
+	if errors.As(err, &inputErr) {
+		return inputErr
+	}
+
+	return err
+}
+
+// checkRequest runs the preflight on every image url passed to an API route
+func (p *Preflight) checkRequest(ctx context.Context, apiURL string) error {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return err
+	}
+
+	query := u.Query()
+	for _, key := range []string{"url", "url2"} {
+		if imageURL := query.Get(key); imageURL != "" {
+			checkCtx, cancel := withTimeout(ctx, p.Clock, p.timeout())
+			err = p.check(checkCtx, imageURL)
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
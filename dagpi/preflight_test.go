@@ -0,0 +1,175 @@
+package dagpi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func TestPreflightAcceptsImage(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	preflight := &dagpi.Preflight{AllowPrivate: true}
+	if err := preflight.Check(server.URL + "/assets/avatar.png"); err != nil {
+		t.Fatalf("Check() = %v, want nil", err)
+	}
+}
+
+func TestPreflightRejects(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	text := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not an image at all"))
+	}))
+	defer text.Close()
+
+	tests := []struct {
+		name      string
+		preflight *dagpi.Preflight
+		url       string
+		reason    string
+	}{
+		{"scheme", &dagpi.Preflight{AllowPrivate: true}, "ftp://example.com/a.png", "scheme"},
+		{"missing host", &dagpi.Preflight{AllowPrivate: true}, "http:///a.png", "missing host"},
+		{"private host", &dagpi.Preflight{}, server.URL + "/assets/avatar.png", "private address"},
+		{"not an image", &dagpi.Preflight{AllowPrivate: true}, text.URL, "not an image"},
+		{"too big", &dagpi.Preflight{AllowPrivate: true, MaxBytes: 10}, server.URL + "/assets/avatar.png", "limit is 10"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.preflight.Check(test.url)
+
+			var inputErr *dagpi.InputError
+			if !errors.As(err, &inputErr) {
+				t.Fatalf("Check() = %v, want an InputError", err)
+			}
+			if !strings.Contains(inputErr.Error(), test.reason) {
+				t.Errorf("Check() = %v, want it to mention %q", err, test.reason)
+			}
+		})
+	}
+}
+
+func TestPreflightUnknownSize(t *testing.T) {
+	image := dagpitest.PlaceholderPNG("chunked")
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"chunked", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.(http.Flusher).Flush()
+			_, _ = w.Write(image)
+		}},
+		{"range without total", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", "bytes 0-511/*")
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(image[:512])
+				return
+			}
+			w.(http.Flusher).Flush()
+			_, _ = w.Write(image)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			small := &dagpi.Preflight{AllowPrivate: true, MaxBytes: 10}
+			var inputErr *dagpi.InputError
+			if err := small.Check(server.URL); !errors.As(err, &inputErr) {
+				t.Errorf("Check() with MaxBytes 10 = %v, want an InputError", err)
+			}
+
+			large := &dagpi.Preflight{AllowPrivate: true, MaxBytes: int64(len(image))}
+			if err := large.Check(server.URL); err != nil {
+				t.Errorf("Check() with MaxBytes %d = %v, want nil", len(image), err)
+			}
+		})
+	}
+}
+
+func TestClientPreflight(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	client := server.Client()
+	client.Preflight = &dagpi.Preflight{}
+
+	_, err := client.Pixelate(server.URL + "/assets/avatar.png")
+	var inputErr *dagpi.InputError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("Pixelate() = %v, want an InputError", err)
+	}
+	if server.Requests("image/pixel") != 0 {
+		t.Errorf("the API got %d requests, want 0", server.Requests("image/pixel"))
+	}
+}
+
+func TestPreflightTimesOutOnClock(t *testing.T) {
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+
+	clock := dagpitest.NewClock(time.Time{})
+	preflight := &dagpi.Preflight{AllowPrivate: true, Timeout: time.Minute, Clock: clock}
+	done := make(chan error, 1)
+	go func() {
+		done <- preflight.Check(stalled.URL + "/avatar.png")
+	}()
+
+	clock.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("Check() = %v before the timeout", err)
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	var inputErr *dagpi.InputError
+	if err := <-done; !errors.As(err, &inputErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Check() = %v, want an InputError for the deadline", err)
+	}
+	if clock.Timers() != 0 {
+		t.Errorf("%d timers left waiting, want the timeout's stopped", clock.Timers())
+	}
+}
+
+func TestClientPreflightTimesOutOnClock(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+
+	clock := dagpitest.NewClock(time.Time{})
+	client := server.Client()
+	client.Preflight = &dagpi.Preflight{AllowPrivate: true, Timeout: 5 * time.Second, Clock: clock}
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Pixelate(stalled.URL + "/avatar.png")
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Second)
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pixelate() = %v, want the preflight to time out", err)
+	}
+	if server.Requests("image/pixel") != 0 {
+		t.Error("the url was sent to the API after the preflight timed out")
+	}
+}
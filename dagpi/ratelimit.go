@@ -0,0 +1,102 @@
+package dagpi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles the requests a Client sends to the API
+type RateLimiter interface {
+	// Wait blocks until a request may be sent or the context is done
+	Wait(ctx context.Context) error
+}
+
+// Limiter is a token bucket RateLimiter that allows bursts of up to limit requests
+type Limiter struct {
+	// Clock defaults to SystemClock
+	Clock Clock
+
+	mu       sync.Mutex
+	limit    float64
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+// NewLimiter allows limit requests every per, e.g. NewLimiter(60, time.Minute) for the default Dagpi limit.
+// A limit or per of zero or less doesn't limit anything.
+func NewLimiter(limit int, per time.Duration) *Limiter {
+	if limit <= 0 || per <= 0 {
+		return &Limiter{}
+	}
+
+	return &Limiter{
+		limit:    float64(limit),
+		interval: per / time.Duration(limit),
+		tokens:   float64(limit),
+	}
+}
+
+// unlimited reports whether the limiter lets every request through, like the ones from NewLimiter(0, per)
+func (l *Limiter) unlimited() bool {
+	return l.interval <= 0
+}
+
+// refills the bucket for the time passed since the last call, must hold l.mu
+func (l *Limiter) refill(now time.Time) {
+	if l.last.IsZero() {
+		l.last = now
+	}
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.limit {
+		l.tokens = l.limit
+	}
+	l.last = now
+}
+
+// Allow takes a token if one is available without waiting
+func (l *Limiter) Allow() bool {
+	if l.unlimited() {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(clockOrSystem(l.Clock).Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
+
+// Wait takes a token, sleeping until one is available
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.unlimited() {
+		return ctx.Err()
+	}
+
+	clock := clockOrSystem(l.Clock)
+	for {
+		l.mu.Lock()
+		l.refill(clock.Now())
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) * float64(l.interval))
+		l.mu.Unlock()
+
+		timer := clock.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package dagpi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/beamer64/godagpi/dagpi"
+	"github.com/beamer64/godagpi/dagpi/dagpitest"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	limiter := dagpi.NewLimiter(2, time.Second)
+	limiter.Clock = clock
+
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("Allow() refused a request within the burst")
+	}
+	if limiter.Allow() {
+		t.Fatal("Allow() let a third request through the burst of 2")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if !limiter.Allow() {
+		t.Error("Allow() refused a request after a token was refilled")
+	}
+	if limiter.Allow() {
+		t.Error("Allow() refilled more than one token in 500ms")
+	}
+
+	clock.Advance(time.Hour)
+	if !limiter.Allow() || !limiter.Allow() || limiter.Allow() {
+		t.Error("the bucket refilled past its limit")
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	limiter := dagpi.NewLimiter(1, time.Second)
+	limiter.Clock = clock
+
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.Wait(context.Background())
+	}()
+
+	clock.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("Wait() returned %v before the clock moved", err)
+	default:
+	}
+
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("Wait() = %v", err)
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	clock := dagpitest.NewClock(time.Time{})
+	limiter := dagpi.NewLimiter(1, time.Minute)
+	limiter.Clock = clock
+	limiter.Allow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.Wait(ctx)
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Wait() = %v, want context.Canceled", err)
+	}
+	if clock.Timers() != 0 {
+		t.Error("Wait() left its timer running")
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	for _, limiter := range []*dagpi.Limiter{dagpi.NewLimiter(0, time.Minute), dagpi.NewLimiter(-1, time.Minute), dagpi.NewLimiter(5, 0), {}} {
+		for i := 0; i < 100; i++ {
+			if !limiter.Allow() {
+				t.Fatal("Allow() refused a request on an unlimited limiter")
+			}
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait() = %v", err)
+			}
+		}
+	}
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	server := dagpitest.NewServer("")
+	defer server.Close()
+
+	clock := dagpitest.NewClock(time.Time{})
+	limiter := dagpi.NewLimiter(1, time.Minute)
+	limiter.Clock = clock
+
+	client := server.Client()
+	client.RateLimiter = limiter
+	if _, err := client.Joke(); err != nil {
+		t.Fatalf("Joke() = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Joke()
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	if server.Requests("data/joke") != 1 {
+		t.Fatalf("the API got %d requests while the limiter was empty, want 1", server.Requests("data/joke"))
+	}
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Fatalf("Joke() = %v", err)
+	}
+	if server.Requests("data/joke") != 2 {
+		t.Errorf("the API got %d requests, want 2", server.Requests("data/joke"))
+	}
+}
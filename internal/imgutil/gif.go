@@ -0,0 +1,101 @@
+package imgutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+// MaxGIFPixels is the most pixels DecodeGIF accepts for the canvas times the number of frames. Drawing every frame
+// on the full canvas takes that many whatever size the frames are, and tiny frames on a big canvas compress to almost nothing.
+const MaxGIFPixels = 2 * MaxPixels
+
+// DecodeGIF decodes every frame of a gif, refusing gifs with a canvas over MaxPixels or frames over MaxGIFPixels
+func DecodeGIF(data []byte) (*gif.GIF, error) {
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is over %d", ErrTooLarge, config.Width, config.Height, MaxPixels)
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := canvasBounds(g)
+	if int64(bounds.Dx())*int64(bounds.Dy())*int64(len(g.Image)) > MaxGIFPixels {
+		return nil, fmt.Errorf("%w: %d frames of %dx%d are over %d", ErrTooLarge, len(g.Image), bounds.Dx(), bounds.Dy(), MaxGIFPixels)
+	}
+
+	return g, nil
+}
+
+// canvasBounds is the area every frame of a gif is drawn on
+func canvasBounds(g *gif.GIF) image.Rectangle {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+
+	return bounds
+}
+
+// drawFrames draws the frames of a gif in turn on one canvas the way a viewer shows them, following each frame's
+// disposal. visit is called with the canvas as it looks for frame i and must copy it to keep it, returning false stops.
+func drawFrames(g *gif.GIF, visit func(i int, canvas *image.NRGBA) bool) {
+	canvas := image.NewNRGBA(canvasBounds(g))
+	var previous *image.NRGBA
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			if previous == nil {
+				previous = image.NewNRGBA(canvas.Bounds())
+			}
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		if !visit(i, canvas) {
+			return
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+}
+
+// Frames draws every frame of a gif onto the full canvas the way a viewer shows it, following each frame's disposal
+func Frames(g *gif.GIF) []*image.NRGBA {
+	frames := make([]*image.NRGBA, 0, len(g.Image))
+	drawFrames(g, func(i int, canvas *image.NRGBA) bool {
+		frames = append(frames, NRGBA(canvas))
+		return true
+	})
+
+	return frames
+}
+
+// Frame draws the frames of a gif up to index and returns frame index as a viewer shows it
+func Frame(g *gif.GIF, index int) *image.NRGBA {
+	var frame *image.NRGBA
+	drawFrames(g, func(i int, canvas *image.NRGBA) bool {
+		if i < index {
+			return true
+		}
+		frame = NRGBA(canvas)
+		return false
+	})
+
+	return frame
+}
@@ -0,0 +1,135 @@
+// Package imgutil holds the small image helpers shared by the client and the local renderer
+package imgutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	// decoders for every format the API hands out or accepts
+	_ "image/gif"
+	_ "image/jpeg"
+)
+
+// MaxPixels is the largest image Decode accepts. A small, highly compressed file can claim dimensions
+// that would take gigabytes to decode, so they are checked before any pixel is.
+const MaxPixels = 5000 * 5000
+
+// ErrTooLarge is returned by Decode for images with more than MaxPixels pixels
+var ErrTooLarge = errors.New("image has too many pixels to decode")
+
+// Decode decodes a png, jpeg or gif, only the first frame of a gif is returned
+func Decode(data []byte) (image.Image, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d is over %d", ErrTooLarge, config.Width, config.Height, MaxPixels)
+	}
+
+	return image.Decode(bytes.NewReader(data))
+}
+
+// EncodePNG encodes img as a png
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NRGBA returns a copy of img as *image.NRGBA with its bounds moved to the origin
+func NRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+
+	return dst
+}
+
+// Resize scales img to w x h. Shrinking averages every source pixel that falls in a destination pixel,
+// growing samples the nearest pixel.
+func Resize(img image.Image, w int, h int) *image.NRGBA {
+	src := NRGBA(img)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if sw == 0 || sh == 0 || w <= 0 || h <= 0 {
+		return dst
+	}
+
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := (y + 1) * sh / h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := (x + 1) * sw / w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+
+	return dst
+}
+
+// Fit returns the largest size with the aspect ratio of w x h that fits in maxW x maxH, never growing the image
+func Fit(w int, h int, maxW int, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return w, h
+	}
+	if w*maxH > h*maxW {
+		return maxW, max1(h * maxW / w)
+	}
+
+	return max1(w * maxH / h), maxH
+}
+
+func max1(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// Luma is the perceived brightness of a color from 0 to 255
+func Luma(c color.NRGBA) uint8 {
+	return uint8((299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000)
+}
+
+// Clamp limits v to a valid 8 bit channel value
+func Clamp(v int) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+
+	return uint8(v)
+}
@@ -0,0 +1,147 @@
+package imgutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+)
+
+// Quantize picks a palette of n colors for all frames with median cut on a sample of their pixels.
+// The first entry is always transparent. Frames with fewer than n colors keep their exact colors.
+func Quantize(frames []*image.NRGBA, n int) color.Palette {
+	if palette := exactPalette(frames, n); palette != nil {
+		return palette
+	}
+
+	var pixels [][3]uint8
+	perFrame := 16384/len(frames) + 1
+	for _, frame := range frames {
+		step := len(frame.Pix)/4/perFrame + 1
+		for i := 0; i < len(frame.Pix); i += 4 * step {
+			if frame.Pix[i+3] >= 128 {
+				pixels = append(pixels, [3]uint8{frame.Pix[i], frame.Pix[i+1], frame.Pix[i+2]})
+			}
+		}
+	}
+
+	boxes := []colorBox{newColorBox(pixels)}
+	for len(boxes) < n-1 {
+		// split the box with the widest channel range at its median
+		widest := -1
+		for i, box := range boxes {
+			if len(box.pixels) > 1 && box.spread > 0 && (widest < 0 || box.spread > boxes[widest].spread) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+
+		box := boxes[widest]
+		sort.Slice(box.pixels, func(i, j int) bool { return box.pixels[i][box.channel] < box.pixels[j][box.channel] })
+		boxes[widest] = newColorBox(box.pixels[:len(box.pixels)/2])
+		boxes = append(boxes, newColorBox(box.pixels[len(box.pixels)/2:]))
+	}
+
+	palette := color.Palette{color.NRGBA{}}
+	for _, box := range boxes {
+		if len(box.pixels) == 0 {
+			continue
+		}
+		var r, g, b int
+		for _, p := range box.pixels {
+			r += int(p[0])
+			g += int(p[1])
+			b += int(p[2])
+		}
+		count := len(box.pixels)
+		palette = append(palette, color.NRGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 255})
+	}
+
+	return palette
+}
+
+// exactPalette returns every opaque color in frames, or nil when there are too many to fit in n
+func exactPalette(frames []*image.NRGBA, n int) color.Palette {
+	seen := make(map[[3]uint8]bool)
+	palette := color.Palette{color.NRGBA{}}
+	for _, frame := range frames {
+		for i := 0; i < len(frame.Pix); i += 4 {
+			if frame.Pix[i+3] < 128 {
+				continue
+			}
+			p := [3]uint8{frame.Pix[i], frame.Pix[i+1], frame.Pix[i+2]}
+			if seen[p] {
+				continue
+			}
+			if len(palette) == n {
+				return nil
+			}
+			seen[p] = true
+			palette = append(palette, color.NRGBA{R: p[0], G: p[1], B: p[2], A: 255})
+		}
+	}
+
+	return palette
+}
+
+// colorBox is a group of pixels in median cut along with the channel they differ the most in
+type colorBox struct {
+	pixels  [][3]uint8
+	channel int
+	spread  int
+}
+
+func newColorBox(pixels [][3]uint8) colorBox {
+	box := colorBox{pixels: pixels}
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := uint8(255), uint8(0)
+		for _, p := range pixels {
+			if p[ch] < lo {
+				lo = p[ch]
+			}
+			if p[ch] > hi {
+				hi = p[ch]
+			}
+		}
+		if int(hi)-int(lo) > box.spread {
+			box.channel, box.spread = ch, int(hi)-int(lo)
+		}
+	}
+
+	return box
+}
+
+// Paletted maps a frame to the palette, mostly transparent pixels use the transparent first entry
+func Paletted(frame *image.NRGBA, palette color.Palette, dither bool) *image.Paletted {
+	dst := image.NewPaletted(frame.Bounds(), palette)
+	if dither {
+		draw.FloydSteinberg.Draw(dst, dst.Bounds(), frame, image.Point{})
+	}
+
+	opaque := palette[1:]
+	lookup := map[color.NRGBA]uint8{}
+	for y := 0; y < frame.Bounds().Dy(); y++ {
+		for x := 0; x < frame.Bounds().Dx(); x++ {
+			c := frame.NRGBAAt(x, y)
+			if c.A < 128 {
+				dst.SetColorIndex(x, y, 0)
+				continue
+			}
+			if dither {
+				continue
+			}
+
+			c.A = 255
+			index, ok := lookup[c]
+			if !ok {
+				index = uint8(opaque.Index(c) + 1)
+				lookup[c] = index
+			}
+			dst.SetColorIndex(x, y, index)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,177 @@
+// Package routes lists the API routes the client calls along with what they take and return.
+// It is shared by the fake server, the contract suite and the benchmark tool.
+package routes
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Route is a single API route
+type Route struct {
+	// Path without a leading slash, like "data/joke" or "image/pixel"
+	Path string
+
+	// Params are the query params an image route needs
+	Params []string
+
+	// Fields a data route always answers with
+	Fields []Field
+
+	// Animated image routes answer with a gif, the rest with a png
+	Animated bool
+}
+
+// Field is a json field and its type, one of string, number, boolean, object or array.
+// Nested fields are named with dots, like "Data.name".
+type Field struct {
+	Name string
+	Type string
+}
+
+// Name is the path without its "data/" or "image/" prefix
+func (r Route) Name() string {
+	return r.Path[strings.Index(r.Path, "/")+1:]
+}
+
+// IsImage reports whether the route answers with an image
+func (r Route) IsImage() bool {
+	return strings.HasPrefix(r.Path, "image/")
+}
+
+// samples are the values passed for each param in Query
+var samples = map[string]string{
+	"flag":        "gay",
+	"username":    "dagpi",
+	"text":        "contract test",
+	"top_text":    "top text",
+	"bottom_text": "bottom text",
+	"dark":        "false",
+}
+
+// Query fills in every param the route takes, imageURL is used for url and url2
+func (r Route) Query(imageURL string) url.Values {
+	query := url.Values{}
+	for _, param := range r.Params {
+		if param == "url" || param == "url2" {
+			query.Set(param, imageURL)
+		} else {
+			query.Set(param, samples[param])
+		}
+	}
+
+	return query
+}
+
+// Data routes answer with json
+var Data = []Route{
+	{Path: "data/wtp", Fields: []Field{{"Data", "object"}, {"Data.name", "string"}, {"Data.id", "number"}, {"Data.Type", "array"}, {"Data.abilities", "array"}, {"question", "string"}, {"answer", "string"}}},
+	{Path: "data/roast", Fields: []Field{{"roast", "string"}}},
+	{Path: "data/joke", Fields: []Field{{"id", "string"}, {"joke", "string"}}},
+	{Path: "data/fact", Fields: []Field{{"fact", "string"}}},
+	{Path: "data/8ball", Fields: []Field{{"response", "string"}}},
+	{Path: "data/yomama", Fields: []Field{{"description", "string"}}},
+	{Path: "data/waifu", Fields: []Field{{"id", "number"}, {"name", "string"}, {"display_picture", "string"}, {"description", "string"}, {"series", "object"}}},
+	{Path: "data/pickupline", Fields: []Field{{"category", "string"}, {"joke", "string"}}},
+	{Path: "data/headline", Fields: []Field{{"text", "string"}, {"fake", "boolean"}}},
+	{Path: "data/logo", Fields: []Field{{"question", "string"}, {"answer", "string"}, {"brand", "string"}, {"clue", "string"}, {"hint", "string"}, {"wiki_url", "string"}}},
+	{Path: "data/flag", Fields: []Field{{"Data", "object"}, {"Data.name", "object"}, {"flag", "string"}}},
+	{Path: "data/captcha", Fields: []Field{{"image", "string"}, {"answer", "string"}}},
+	{Path: "data/typeracer", Fields: []Field{{"image", "string"}, {"sentence", "string"}}},
+}
+
+var (
+	single   = []string{"url"}
+	double   = []string{"url", "url2"}
+	captions = []string{"url", "top_text", "bottom_text"}
+	messages = []string{"url", "username", "text", "dark"}
+)
+
+// Image routes answer with a png or a gif
+var Image = []Route{
+	{Path: "image/pixel", Params: single},
+	{Path: "image/mirror", Params: single},
+	{Path: "image/flip", Params: single},
+	{Path: "image/colors", Params: single},
+	{Path: "image/america", Params: single},
+	{Path: "image/communism", Params: single},
+	{Path: "image/triggered", Params: single, Animated: true},
+	{Path: "image/expand", Params: single},
+	{Path: "image/wasted", Params: single},
+	{Path: "image/sketch", Params: single},
+	{Path: "image/spin", Params: single, Animated: true},
+	{Path: "image/petpet", Params: single, Animated: true},
+	{Path: "image/bonk", Params: single, Animated: true},
+	{Path: "image/bomb", Params: single, Animated: true},
+	{Path: "image/shake", Params: single, Animated: true},
+	{Path: "image/invert", Params: single},
+	{Path: "image/sobel", Params: single},
+	{Path: "image/hog", Params: single},
+	{Path: "image/triangle", Params: single},
+	{Path: "image/blur", Params: single},
+	{Path: "image/rgb", Params: single},
+	{Path: "image/angel", Params: single},
+	{Path: "image/satan", Params: single},
+	{Path: "image/delete", Params: single},
+	{Path: "image/fedora", Params: single},
+	{Path: "image/hitler", Params: single},
+	{Path: "image/lego", Params: single},
+	{Path: "image/wanted", Params: single},
+	{Path: "image/stringify", Params: single},
+	{Path: "image/burn", Params: single},
+	{Path: "image/earth", Params: single, Animated: true},
+	{Path: "image/freeze", Params: single},
+	{Path: "image/ground", Params: single},
+	{Path: "image/mosiac", Params: single},
+	{Path: "image/sith", Params: single},
+	{Path: "image/jail", Params: single},
+	{Path: "image/shatter", Params: single},
+	{Path: "image/pride", Params: []string{"url", "flag"}},
+	{Path: "image/trash", Params: single},
+	{Path: "image/deepfry", Params: single},
+	{Path: "image/ascii", Params: single},
+	{Path: "image/charcoal", Params: single},
+	{Path: "image/poster", Params: single},
+	{Path: "image/sepia", Params: single},
+	{Path: "image/swirl", Params: single},
+	{Path: "image/paint", Params: single},
+	{Path: "image/night", Params: single},
+	{Path: "image/rainbow", Params: single},
+	{Path: "image/magik", Params: single},
+	{Path: "image/5g1g", Params: double},
+	{Path: "image/whyareyougay", Params: double},
+	{Path: "image/slap", Params: double},
+	{Path: "image/obama", Params: double},
+	{Path: "image/tweet", Params: []string{"url", "username", "text"}},
+	{Path: "image/yt", Params: messages},
+	{Path: "image/discord", Params: messages},
+	{Path: "image/retromeme", Params: captions},
+	{Path: "image/motiv", Params: captions},
+	{Path: "image/modernmeme", Params: []string{"url", "text"}},
+	{Path: "image/elmo", Params: single},
+	{Path: "image/tv", Params: single, Animated: true},
+	{Path: "image/rain", Params: single, Animated: true},
+	{Path: "image/glitch", Params: single, Animated: true},
+	{Path: "image/glitchstatic", Params: single},
+	{Path: "image/album", Params: single},
+}
+
+// All returns the data routes followed by the image routes
+func All() []Route {
+	all := make([]Route, 0, len(Data)+len(Image))
+	all = append(all, Data...)
+
+	return append(all, Image...)
+}
+
+// Find looks a route up by its path, leading and trailing slashes are ignored
+func Find(path string) (Route, bool) {
+	path = strings.Trim(path, "/")
+	for _, route := range All() {
+		if route.Path == path {
+			return route, true
+		}
+	}
+
+	return Route{}, false
+}